@@ -0,0 +1,94 @@
+package iplib
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ReverseZone describes a single reverse-DNS zone needed to delegate all or
+// part of a Net
+type ReverseZone struct {
+	// Name is the zone name, without a trailing dot
+	Name string
+
+	// Network is the portion of the original Net served by this zone
+	Network Net
+
+	// Classless is true if Name is an RFC2317 classless delegation, which is
+	// used for v4 blocks smaller than a /24
+	Classless bool
+
+	// Parent is the octet-aligned zone that holds the CNAME records pointing
+	// into a classless delegation. It is empty unless Classless is true
+	Parent string
+}
+
+// ReverseZones returns the list of reverse-DNS zones required to cover the
+// represented network, in address order.
+//
+// For IPv4 the zones are aligned on octet boundaries under "in-addr.arpa",
+// so a /22 is split into four /24 zones. Blocks smaller than a /24 (a /25
+// through a /31) cannot be delegated on an octet boundary and are instead
+// given an RFC2317 classless name of the form "<first-octet>/<masklen>" in
+// the enclosing /24:
+//
+// Net{192.168.1.0/22}.ReverseZones()  -> [0.168.192.in-addr.arpa, 1.168.192.in-addr.arpa, 2.168.192.in-addr.arpa, 3.168.192.in-addr.arpa]
+// Net{192.168.1.64/26}.ReverseZones() -> [64/26.1.168.192.in-addr.arpa]
+//
+// For IPv6 the zones are aligned on nibble boundaries under "ip6.arpa", so a
+// /63 is split into two /64 zones.
+func (n Net) ReverseZones() []ReverseZone {
+	ones, _ := n.Mask.Size()
+
+	if n.version == 4 && ones > 24 && ones < 32 {
+		ip := ForceIP4(n.NetworkAddress())
+		parent := arpaZoneName(ip, 24)
+		return []ReverseZone{
+			{
+				Name:      fmt.Sprintf("%d/%d.%s", ip[3], ones, parent),
+				Network:   n,
+				Classless: true,
+				Parent:    parent,
+			},
+		}
+	}
+
+	step := 4
+	if n.version == 4 {
+		step = 8
+	}
+
+	masklen := ones
+	if r := ones % step; r != 0 {
+		masklen = ones + step - r
+	}
+
+	subnets := []Net{n}
+	if masklen != ones {
+		subnets, _ = n.Subnet(masklen)
+	}
+
+	zones := make([]ReverseZone, len(subnets))
+	for i, sn := range subnets {
+		zones[i] = ReverseZone{
+			Name:    arpaZoneName(sn.NetworkAddress(), masklen),
+			Network: sn,
+		}
+	}
+	return zones
+}
+
+// arpaZoneName returns the ARPA domain for the first masklen bits of ip.
+// masklen must fall on an octet boundary for v4 or a nibble boundary for v6
+func arpaZoneName(ip net.IP, masklen int) string {
+	var drop int
+	if EffectiveVersion(ip) == 4 {
+		drop = (32 - masklen) / 8
+	} else {
+		drop = (128 - masklen) / 4
+	}
+
+	labels := strings.Split(IPToARPA(ip), ".")
+	return strings.Join(labels[drop:], ".")
+}
@@ -0,0 +1,92 @@
+package iplib
+
+import (
+	"testing"
+)
+
+var ReverseZoneTests = []struct {
+	in        string
+	zones     []string
+	classless bool
+	parent    string
+}{
+	{
+		"192.168.0.0/16",
+		[]string{"168.192.in-addr.arpa"},
+		false,
+		"",
+	},
+	{
+		"192.168.4.0/22",
+		[]string{"4.168.192.in-addr.arpa", "5.168.192.in-addr.arpa", "6.168.192.in-addr.arpa", "7.168.192.in-addr.arpa"},
+		false,
+		"",
+	},
+	{
+		"10.0.0.0/7",
+		[]string{"10.in-addr.arpa", "11.in-addr.arpa"},
+		false,
+		"",
+	},
+	{
+		"192.168.1.64/26",
+		[]string{"64/26.1.168.192.in-addr.arpa"},
+		true,
+		"1.168.192.in-addr.arpa",
+	},
+	{
+		"192.168.1.5/32",
+		[]string{"5.1.168.192.in-addr.arpa"},
+		false,
+		"",
+	},
+	{
+		"0.0.0.0/0",
+		[]string{"in-addr.arpa"},
+		false,
+		"",
+	},
+	{
+		"2001:db8::/32",
+		[]string{"8.b.d.0.1.0.0.2.ip6.arpa"},
+		false,
+		"",
+	},
+	{
+		"2001:db8::/63",
+		[]string{"0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", "1.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+		false,
+		"",
+	},
+	{
+		"2001:db8:8000::/34",
+		[]string{"8.8.b.d.0.1.0.0.2.ip6.arpa", "9.8.b.d.0.1.0.0.2.ip6.arpa", "a.8.b.d.0.1.0.0.2.ip6.arpa", "b.8.b.d.0.1.0.0.2.ip6.arpa"},
+		false,
+		"",
+	},
+}
+
+func TestNet_ReverseZones(t *testing.T) {
+	for _, tt := range ReverseZoneTests {
+		_, n, _ := ParseCIDR(tt.in)
+		zones := n.ReverseZones()
+		if len(zones) != len(tt.zones) {
+			t.Errorf("On Net{%s}.ReverseZones() expected %d zones, got %d", tt.in, len(tt.zones), len(zones))
+			continue
+		}
+		for i, z := range zones {
+			if z.Name != tt.zones[i] {
+				t.Errorf("On Net{%s}.ReverseZones() [%d] expected '%s' got '%s'", tt.in, i, tt.zones[i], z.Name)
+			}
+			if z.Classless != tt.classless {
+				t.Errorf("On Net{%s}.ReverseZones() [%d] expected classless %t got %t", tt.in, i, tt.classless, z.Classless)
+			}
+			if z.Parent != tt.parent {
+				t.Errorf("On Net{%s}.ReverseZones() [%d] expected parent '%s' got '%s'", tt.in, i, tt.parent, z.Parent)
+			}
+			if !n.ContainsNet(z.Network) {
+				t.Errorf("On Net{%s}.ReverseZones() [%d] zone network %s is outside the block", tt.in, i, z.Network.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package iplib
+
+import (
+	"net"
+)
+
+// IP4ToIP6Embedded embeds a v4 address in the supplied v6 prefix as
+// described in RFC6052, producing an "IPv4-embedded IPv6 address" suitable
+// for use with NAT64 and DNS64. Using the Well-Known Prefix 64:ff9b::/96:
+//
+// IP4ToIP6Embedded(192.0.2.33, Net{64:ff9b::/96}) -> 64:ff9b::c000:221
+//
+// The prefix must be a v6 network with a mask length of 32, 40, 48, 56, 64
+// or 96, otherwise an ErrBadMaskLength will be returned. Per the RFC bits 64
+// through 71 of the address (the "u" octet) are skipped over and always set
+// to zero, as is any suffix following the v4 address. A /96 prefix includes
+// the u octet and so must have it set to zero, otherwise ErrNonZeroUOctet is
+// returned.
+func IP4ToIP6Embedded(ip net.IP, prefix Net) (net.IP, error) {
+	if EffectiveVersion(ip) != 4 {
+		return nil, ErrNotIP4
+	}
+	if prefix.version != 6 {
+		return nil, ErrNotIP6
+	}
+	pos, err := rfc6052Offset(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	xip := make(net.IP, 16)
+	copy(xip, prefix.NetworkAddress())
+	for _, b := range ip.To4() {
+		if pos == 8 {
+			pos++
+		}
+		xip[pos] = b
+		pos++
+	}
+	return xip, nil
+}
+
+// IP6EmbeddedToIP4 extracts the v4 address embedded in an RFC6052
+// "IPv4-embedded IPv6 address" using the supplied v6 prefix. It is the
+// inverse of IP4ToIP6Embedded():
+//
+// IP6EmbeddedToIP4(64:ff9b::c000:221, Net{64:ff9b::/96}) -> 192.0.2.33
+//
+// If the address is not part of the prefix an ErrAddressOutOfRange is
+// returned, and if the prefix is not of a length permitted by RFC6052 the
+// error will be ErrBadMaskLength, or ErrNonZeroUOctet for a /96 prefix with a
+// non-zero "u" octet. RFC6052 requires the "u" octet, bits 64 through 71, to
+// be zero; if it is not the address is malformed and ErrNoEmbeddedIP4 is
+// returned.
+func IP6EmbeddedToIP4(ip net.IP, prefix Net) (net.IP, error) {
+	if len(ip) != 16 || Version(ip) != 6 {
+		return nil, ErrNotIP6
+	}
+	if prefix.version != 6 {
+		return nil, ErrNotIP6
+	}
+	pos, err := rfc6052Offset(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !prefix.Contains(ip) {
+		return nil, ErrAddressOutOfRange
+	}
+	if ip[8] != 0 {
+		return nil, ErrNoEmbeddedIP4
+	}
+
+	xip := make(net.IP, 4)
+	for i := range xip {
+		if pos == 8 {
+			pos++
+		}
+		xip[i] = ip[pos]
+		pos++
+	}
+	return xip, nil
+}
+
+// rfc6052Offset returns the byte offset at which a v4 address begins in an
+// RFC6052 address built on prefix
+func rfc6052Offset(prefix Net) (int, error) {
+	ones, _ := prefix.Mask.Size()
+	switch ones {
+	case 32, 40, 48, 56, 64:
+		return ones / 8, nil
+	case 96:
+		if prefix.NetworkAddress()[8] != 0 {
+			return 0, ErrNonZeroUOctet
+		}
+		return ones / 8, nil
+	}
+	return 0, ErrBadMaskLength
+}
@@ -0,0 +1,135 @@
+package iplib
+
+import (
+	"net"
+	"testing"
+)
+
+var IP6EmbeddedTests = []struct {
+	prefix string
+	ip4    string
+	ip6    string
+	err    error
+}{
+	{
+		"2001:db8::/32",
+		"192.0.2.33",
+		"2001:db8:c000:221::",
+		nil,
+	},
+	{
+		"2001:db8:100::/40",
+		"192.0.2.33",
+		"2001:db8:1c0:2:21::",
+		nil,
+	},
+	{
+		"2001:db8:122::/48",
+		"192.0.2.33",
+		"2001:db8:122:c000:2:2100::",
+		nil,
+	},
+	{
+		"2001:db8:122:300::/56",
+		"192.0.2.33",
+		"2001:db8:122:3c0:0:221::",
+		nil,
+	},
+	{
+		"2001:db8:122:344::/64",
+		"192.0.2.33",
+		"2001:db8:122:344:c0:2:2100:0",
+		nil,
+	},
+	{
+		"2001:db8:122:344::/96",
+		"192.0.2.33",
+		"2001:db8:122:344::192.0.2.33",
+		nil,
+	},
+	{
+		"64:ff9b::/96",
+		"192.0.2.33",
+		"64:ff9b::c000:221",
+		nil,
+	},
+	{
+		"2001:db8::ff:ffff:0:0/96",
+		"192.0.2.33",
+		"2001:db8::ff:ffff:c000:221",
+		nil,
+	},
+	{
+		"2001:db8:0:0:ff00::/96",
+		"192.0.2.33",
+		"",
+		ErrNonZeroUOctet,
+	},
+	{
+		"64:ff9b:1::/48",
+		"10.1.2.3",
+		"64:ff9b:1:a01:2:300::",
+		nil,
+	},
+	{
+		"2001:db8::/33",
+		"192.0.2.33",
+		"",
+		ErrBadMaskLength,
+	},
+	{
+		"192.168.0.0/16",
+		"192.0.2.33",
+		"",
+		ErrNotIP6,
+	},
+}
+
+func TestIP4ToIP6Embedded(t *testing.T) {
+	for i, tt := range IP6EmbeddedTests {
+		_, prefix, _ := ParseCIDR(tt.prefix)
+		ip, err := IP4ToIP6Embedded(net.ParseIP(tt.ip4), prefix)
+		if err != tt.err {
+			t.Errorf("[%d] expected error '%v' got '%v'", i, tt.err, err)
+			continue
+		}
+		if tt.err == nil && !ip.Equal(net.ParseIP(tt.ip6)) {
+			t.Errorf("[%d] expected %s got %s", i, tt.ip6, ip)
+		}
+	}
+}
+
+func TestIP6EmbeddedToIP4(t *testing.T) {
+	for i, tt := range IP6EmbeddedTests {
+		if tt.err != nil {
+			continue
+		}
+		_, prefix, _ := ParseCIDR(tt.prefix)
+		ip, err := IP6EmbeddedToIP4(net.ParseIP(tt.ip6), prefix)
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if !ip.Equal(net.ParseIP(tt.ip4)) || len(ip) != 4 {
+			t.Errorf("[%d] expected %s got %s", i, tt.ip4, ip)
+		}
+	}
+
+	_, prefix, _ := ParseCIDR("64:ff9b::/96")
+	if _, err := IP6EmbeddedToIP4(net.ParseIP("2001:db8::c000:221"), prefix); err != ErrAddressOutOfRange {
+		t.Errorf("expected ErrAddressOutOfRange for address outside prefix, got '%v'", err)
+	}
+	if _, err := IP6EmbeddedToIP4(net.ParseIP("192.0.2.33").To4(), prefix); err != ErrNotIP6 {
+		t.Errorf("expected ErrNotIP6 for v4 address, got '%v'", err)
+	}
+
+	_, prefix, _ = ParseCIDR("2001:db8:0:0:ff00::/96")
+	if _, err := IP6EmbeddedToIP4(net.ParseIP("2001:db8::ff00:0:c000:221"), prefix); err != ErrNonZeroUOctet {
+		t.Errorf("expected ErrNonZeroUOctet for /96 prefix with non-zero u-octet, got '%v'", err)
+	}
+
+	_, prefix, _ = ParseCIDR("2001:db8:122:344::/64")
+	if _, err := IP6EmbeddedToIP4(net.ParseIP("2001:db8:122:344:1c0:0:221:0"), prefix); err != ErrNoEmbeddedIP4 {
+		t.Errorf("expected ErrNoEmbeddedIP4 for non-zero u-octet, got '%v'", err)
+	}
+}
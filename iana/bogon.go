@@ -0,0 +1,194 @@
+package iana
+
+import (
+	"math/big"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+// interval is an inclusive range of addresses held as integers
+type interval struct {
+	lo *big.Int
+	hi *big.Int
+}
+
+// ip6NonUnicast is the v6 space outside of 2000::/3, which IANA holds in
+// reserve and does not allocate to the RIRs. It includes ff00::/8
+var ip6NonUnicast = []iplib.Net{
+	getFromCIDR("::/3"),
+	getFromCIDR("4000::/2"),
+	getFromCIDR("8000::/1"),
+}
+
+// Bogons returns an aggregated list of the networks of the given IP version
+// that should never appear as the source or destination of a packet on the
+// public Internet: the martians described by Martians() plus any unallocated
+// space supplied, such as that returned by rir.Unallocated(). Since that only
+// describes 2000::/3, for v6 everything outside of 2000::/3 is treated as
+// unallocated too, multicast ff00::/8 included. Reservations marked globally
+// reachable, such as 64:ff9b::/96, are carved out of the unallocated space.
+// The list is sorted and no two networks in it overlap or can be joined, so
+// it is ready to be turned into an ACL or prefix-list
+func (r *ReservationRegistry) Bogons(version int, unallocated ...iplib.Net) []iplib.Net {
+	if version == 6 {
+		unallocated = append(append([]iplib.Net{}, ip6NonUnicast...), unallocated...)
+	}
+	return r.bogons(version, unallocated)
+}
+
+// Martians returns an aggregated list of the networks of the given IP
+// version that the registry says are not globally reachable or are reserved
+// by protocol, minus any more specific reservations within them that are
+// globally reachable. Reservations whose termination date has passed are
+// ignored
+func (r *ReservationRegistry) Martians(version int) []iplib.Net {
+	return r.bogons(version, nil)
+}
+
+func (r *ReservationRegistry) bogons(version int, unallocated []iplib.Net) []iplib.Net {
+	var set []interval
+	for _, n := range unallocated {
+		if n.Version() == version {
+			set = addInterval(set, netInterval(n))
+		}
+	}
+
+	reservations := []*Reservation{}
+	now := time.Now()
+	for _, res := range r.Reservations() {
+		if res.Network.Version() != version {
+			continue
+		}
+		if !res.TerminationDate.IsZero() && !res.TerminationDate.After(now) {
+			continue
+		}
+		reservations = append(reservations, res)
+	}
+	sort.SliceStable(reservations, func(i, j int) bool {
+		a, _ := reservations[i].Network.Mask.Size()
+		b, _ := reservations[j].Network.Mask.Size()
+		return a < b
+	})
+
+	// Work from the least to the most specific reservation so that, as with
+	// IsValidSource(), the most specific reservation covering an address
+	// decides whether it is a martian
+	for _, res := range reservations {
+		if !res.Global || res.Reserved {
+			set = addInterval(set, netInterval(res.Network))
+		} else {
+			set = subtractInterval(set, netInterval(res.Network))
+		}
+	}
+
+	nets := []iplib.Net{}
+	for _, iv := range set {
+		nets = append(nets, intervalToNets(iv, version)...)
+	}
+	return nets
+}
+
+// addInterval returns the union of set and iv. set must be sorted and
+// contain no overlapping or adjacent intervals, as will the returned set
+func addInterval(set []interval, iv interval) []interval {
+	set = append(set, iv)
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].lo.Cmp(set[j].lo) < 0
+	})
+
+	xset := []interval{set[0]}
+	one := big.NewInt(1)
+	for _, s := range set[1:] {
+		last := &xset[len(xset)-1]
+		next := new(big.Int).Add(last.hi, one)
+		if s.lo.Cmp(next) <= 0 {
+			if s.hi.Cmp(last.hi) > 0 {
+				last.hi = s.hi
+			}
+			continue
+		}
+		xset = append(xset, s)
+	}
+	return xset
+}
+
+// subtractInterval returns the addresses in set that are not in iv
+func subtractInterval(set []interval, iv interval) []interval {
+	xset := []interval{}
+	one := big.NewInt(1)
+	for _, s := range set {
+		if s.hi.Cmp(iv.lo) < 0 || s.lo.Cmp(iv.hi) > 0 {
+			xset = append(xset, s)
+			continue
+		}
+		if s.lo.Cmp(iv.lo) < 0 {
+			xset = append(xset, interval{s.lo, new(big.Int).Sub(iv.lo, one)})
+		}
+		if s.hi.Cmp(iv.hi) > 0 {
+			xset = append(xset, interval{new(big.Int).Add(iv.hi, one), s.hi})
+		}
+	}
+	return xset
+}
+
+func netInterval(n iplib.Net) interval {
+	ip := n.IP.To16()
+	if n.Version() == 4 {
+		ip = n.IP.To4()
+	}
+	ones, bits := n.Mask.Size()
+
+	lo := new(big.Int).SetBytes(ip)
+	hi := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	hi.Add(hi, lo).Sub(hi, big.NewInt(1))
+	return interval{lo, hi}
+}
+
+// intervalToNets returns the smallest list of CIDR blocks covering iv
+func intervalToNets(iv interval, version int) []iplib.Net {
+	bits := 128
+	if version == 4 {
+		bits = 32
+	}
+
+	nets := []iplib.Net{}
+	lo := new(big.Int).Set(iv.lo)
+	for lo.Cmp(iv.hi) <= 0 {
+		size := bits
+		if lo.Sign() != 0 && int(lo.TrailingZeroBits()) < size {
+			size = int(lo.TrailingZeroBits())
+		}
+
+		block := new(big.Int)
+		for {
+			block.Lsh(big.NewInt(1), uint(size))
+			last := new(big.Int).Add(lo, block)
+			if last.Sub(last, big.NewInt(1)).Cmp(iv.hi) <= 0 {
+				break
+			}
+			size--
+		}
+
+		nets = append(nets, bigintToNet(lo, bits, bits-size))
+		lo.Add(lo, block)
+	}
+	return nets
+}
+
+// bigintToNet returns the network at z with the given mask length. NewNet()
+// would make a v4 Net of a v6 network in ::ffff:0:0/96, so those are built
+// from their v4 equivalent with IP4NetToIP6Mapped()
+func bigintToNet(z *big.Int, bits, masklen int) iplib.Net {
+	b := z.Bytes()
+	ip := make(net.IP, bits/8)
+	copy(ip[len(ip)-len(b):], b)
+
+	if bits == 128 && iplib.IsIP4Mapped(ip) {
+		n, _ := iplib.IP4NetToIP6Mapped(iplib.NewNet(ip[12:], masklen-96))
+		return n
+	}
+	return iplib.NewNet(ip, masklen)
+}
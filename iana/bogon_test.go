@@ -0,0 +1,178 @@
+package iana
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kenits/iplib"
+	"github.com/kenits/iplib/rir"
+)
+
+var Martians4 = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/29",
+	"192.0.0.8/32",
+	"192.0.0.11/32",
+	"192.0.0.12/30",
+	"192.0.0.16/28",
+	"192.0.0.32/27",
+	"192.0.0.64/26",
+	"192.0.0.128/25",
+	"192.0.2.0/24",
+	"192.88.99.2/32",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+}
+
+func TestMartians(t *testing.T) {
+	nets := Martians(4)
+	if len(nets) != len(Martians4) {
+		t.Fatalf("expected %d v4 martians, got %d", len(Martians4), len(nets))
+	}
+	for i, n := range nets {
+		if n.String() != Martians4[i] {
+			t.Errorf("expected %s at %d, got %s", Martians4[i], i, n.String())
+		}
+	}
+}
+
+var Martian6Tests = []struct {
+	address string
+	martian bool
+}{
+	{"::", true},
+	{"::1", true},
+	{"64:ff9b:1::1", true},
+	{"100::1", true},
+	{"2001:2::1", true},
+	{"2001:db8::1", true},
+	{"3fff::1", true},
+	{"fd00::1", true},
+	{"fe80::1", true},
+	{"::2", false},
+	{"64:ff9b::1", false},
+	{"2001::1", false},
+	{"2001:1::1", false},
+	{"2001:20::1", false},
+	{"2002::1", false},
+	{"2600::1", false},
+}
+
+func TestMartians6(t *testing.T) {
+	nets := Martians(6)
+	for _, tt := range Martian6Tests {
+		if m := netsContain(nets, net.ParseIP(tt.address)); m != tt.martian {
+			t.Errorf("%s: expected martian %t, got %t", tt.address, tt.martian, m)
+		}
+	}
+	checkAggregated(t, nets)
+
+	mapped := false
+	for _, n := range nets {
+		if n.String() == "::ffff:0.0.0.0/96" {
+			mapped = true
+		}
+	}
+	if !mapped {
+		t.Error("expected ::ffff:0:0/96 to be a martian")
+	}
+	if !netsContain(nets, net.ParseIP("::ffff:10.1.1.1")) || netsContain(nets, net.ParseIP("10.1.1.1").To4()) {
+		t.Error("expected ::ffff:0:0/96 to match only v6 addresses")
+	}
+	checkAllVersion6(t, nets)
+}
+
+// checkAllVersion6 fails if any of nets would be read back as a v4 network,
+// which would turn a v6 ACL entry into a v4 one
+func checkAllVersion6(t *testing.T, nets []iplib.Net) {
+	for _, n := range nets {
+		if n.Version() != 6 {
+			t.Errorf("expected %s to be v6", n.String())
+		}
+		if _, xn, err := iplib.ParseCIDR(n.String()); err != nil || xn.Version() != 6 {
+			t.Errorf("expected %s to parse as a v6 network", n.String())
+		}
+	}
+}
+
+func TestBogons(t *testing.T) {
+	nets := Bogons(4, rir.Unallocated(4)...)
+	if len(nets) != len(Martians4) {
+		t.Fatalf("expected %d v4 bogons, got %d", len(Martians4), len(nets))
+	}
+	if last := nets[len(nets)-1].String(); last != "224.0.0.0/3" {
+		t.Errorf("expected unallocated 224.0.0.0/3 to absorb 240.0.0.0/4, got %s", last)
+	}
+
+	nets = Bogons(6, rir.Unallocated(6)...)
+	for _, s := range []string{"::2", "2d00::1", "2001:db8::1", "2001:5::1", "4000::1", "8000::1", "fe80::1", "ff02::1", "ff0e::1"} {
+		if !netsContain(nets, net.ParseIP(s)) {
+			t.Errorf("expected %s to be a bogon", s)
+		}
+	}
+	for _, s := range []string{"64:ff9b::1", "2001::1", "2001:200::1", "2a00::1"} {
+		if netsContain(nets, net.ParseIP(s)) {
+			t.Errorf("expected %s to not be a bogon", s)
+		}
+	}
+	checkAggregated(t, nets)
+	checkAllVersion6(t, nets)
+
+	// space outside of 2000::/3 is a bogon even without rir data, but not a
+	// martian
+	if !netsContain(Bogons(6), net.ParseIP("4000::1")) {
+		t.Error("expected 4000::1 to be a bogon without any unallocated space")
+	}
+	if netsContain(Martians(6), net.ParseIP("4000::1")) {
+		t.Error("expected 4000::1 to not be a martian")
+	}
+}
+
+func TestRegistry_Bogons(t *testing.T) {
+	r := NewRegistry([]*Reservation{
+		{Network: getFromCIDR("10.0.0.0/9")},
+		{Network: getFromCIDR("10.128.0.0/9")},
+		{Network: getFromCIDR("10.1.0.0/16"), Global: true},
+		{Network: getFromCIDR("10.1.1.0/24"), Global: true, Reserved: true},
+		{Network: getFromCIDR("192.168.0.0/16"), TerminationDate: time.Now().Add(-time.Hour)},
+		{Network: getFromCIDR("172.16.0.0/12"), TerminationDate: time.Now().Add(time.Hour)},
+	})
+
+	expect := []string{"10.0.0.0/16", "10.1.1.0/24", "10.2.0.0/15", "10.4.0.0/14", "10.8.0.0/13", "10.16.0.0/12", "10.32.0.0/11", "10.64.0.0/10", "10.128.0.0/9", "172.16.0.0/12"}
+	_, extra, _ := iplib.ParseCIDR("10.64.0.0/10")
+	nets := r.Bogons(4, extra)
+	if len(nets) != len(expect) {
+		t.Fatalf("expected %d bogons, got %d", len(expect), len(nets))
+	}
+	for i, n := range nets {
+		if n.String() != expect[i] {
+			t.Errorf("expected %s at %d, got %s", expect[i], i, n.String())
+		}
+	}
+}
+
+func checkAggregated(t *testing.T, nets []iplib.Net) {
+	for i := 1; i < len(nets); i++ {
+		if iplib.CompareNets(nets[i-1], nets[i]) >= 0 || nets[i-1].ContainsNet(nets[i]) {
+			t.Errorf("%s and %s are out of order or overlap", nets[i-1].String(), nets[i].String())
+		}
+	}
+}
+
+func netsContain(nets []iplib.Net, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package iana
+
+import (
+	"net"
+	"strings"
+
+	"github.com/kenits/iplib"
+)
+
+// Category is a set of flags describing the kind of address an IP or network
+// is. Since a network may be covered by more than one reservation, categories
+// are combined with bitwise-or and tested with Has()
+type Category uint32
+
+const (
+	CategoryUnspecified Category = 1 << iota
+	CategoryLoopback
+	CategoryPrivate
+	CategorySharedCGN
+	CategoryLinkLocal
+	CategoryMulticast
+	CategoryDocumentation
+	CategoryBenchmarking
+	CategoryULA
+	CategoryBroadcast
+	CategoryGlobalUnicast
+)
+
+var categoryNames = []struct {
+	cat  Category
+	name string
+}{
+	{CategoryUnspecified, "unspecified"},
+	{CategoryLoopback, "loopback"},
+	{CategoryPrivate, "private"},
+	{CategorySharedCGN, "shared-cgn"},
+	{CategoryLinkLocal, "link-local"},
+	{CategoryMulticast, "multicast"},
+	{CategoryDocumentation, "documentation"},
+	{CategoryBenchmarking, "benchmarking"},
+	{CategoryULA, "ula"},
+	{CategoryBroadcast, "broadcast"},
+	{CategoryGlobalUnicast, "global-unicast"},
+}
+
+// categoryRules map reservations onto categories. A reservation matches a
+// rule if it cites the rule's RFC and, where one is given, its title contains
+// the rule's title. Matching on the RFC rather than the network means a
+// ReservationRegistry loaded from a newer copy of IANA's data is classified
+// the same way
+var categoryRules = []struct {
+	rfc   string
+	title string
+	cat   Category
+}{
+	{"RFC1122", "this host", CategoryUnspecified},
+	{"RFC4291", "unspecified", CategoryUnspecified},
+	{"RFC1122", "loopback", CategoryLoopback},
+	{"RFC4291", "loopback", CategoryLoopback},
+	{"RFC1918", "", CategoryPrivate},
+	{"RFC6598", "", CategorySharedCGN},
+	{"RFC3927", "", CategoryLinkLocal},
+	{"RFC4291", "link-local", CategoryLinkLocal},
+	{"RFC5737", "", CategoryDocumentation},
+	{"RFC3849", "", CategoryDocumentation},
+	{"RFC9637", "", CategoryDocumentation},
+	{"RFC2544", "", CategoryBenchmarking},
+	{"RFC5180", "", CategoryBenchmarking},
+	{"RFC4193", "", CategoryULA},
+	{"RFC919", "", CategoryBroadcast},
+}
+
+// Has returns true if every category in x is also in c
+func (c Category) Has(x Category) bool {
+	return c&x == x
+}
+
+// String returns the names of the categories in c separated by '|', for
+// example "private|global-unicast"
+func (c Category) String() string {
+	names := []string{}
+	for _, cn := range categoryNames {
+		if c.Has(cn.cat) {
+			names = append(names, cn.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// MulticastScope is the scope of a multicast address. The values are those
+// of the scope field in an RFC7346 IPv6 multicast address, v4 addresses are
+// mapped onto them using the administratively scoped blocks from RFC2365
+type MulticastScope uint8
+
+const (
+	// ScopeNone is returned for addresses that are not multicast
+	ScopeNone              MulticastScope = 0x0
+	ScopeInterfaceLocal    MulticastScope = 0x1
+	ScopeLinkLocal         MulticastScope = 0x2
+	ScopeRealmLocal        MulticastScope = 0x3
+	ScopeAdminLocal        MulticastScope = 0x4
+	ScopeSiteLocal         MulticastScope = 0x5
+	ScopeOrganizationLocal MulticastScope = 0x8
+	ScopeGlobal            MulticastScope = 0xe
+)
+
+// String returns the name of the scope
+func (s MulticastScope) String() string {
+	switch s {
+	case ScopeNone:
+		return "none"
+	case ScopeInterfaceLocal:
+		return "interface-local"
+	case ScopeLinkLocal:
+		return "link-local"
+	case ScopeRealmLocal:
+		return "realm-local"
+	case ScopeAdminLocal:
+		return "admin-local"
+	case ScopeSiteLocal:
+		return "site-local"
+	case ScopeOrganizationLocal:
+		return "organization-local"
+	case ScopeGlobal:
+		return "global"
+	}
+	return "unassigned"
+}
+
+// GetMulticastScope returns the scope of the supplied multicast address, or
+// ScopeNone if it is not multicast. For v6 this is read from the address
+// itself. For v4 224.0.0.0/24 is link-local, 239.255.0.0/16 is site-local,
+// 239.192.0.0/14 is organization-local, the rest of 239.0.0.0/8 is
+// admin-local and everything else is global
+func GetMulticastScope(ip net.IP) MulticastScope {
+	if !ip.IsMulticast() {
+		return ScopeNone
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		switch {
+		case ip4[0] == 224 && ip4[1] == 0 && ip4[2] == 0:
+			return ScopeLinkLocal
+		case ip4[0] == 239 && ip4[1] == 255:
+			return ScopeSiteLocal
+		case ip4[0] == 239 && ip4[1]&0xfc == 192:
+			return ScopeOrganizationLocal
+		case ip4[0] == 239:
+			return ScopeAdminLocal
+		}
+		return ScopeGlobal
+	}
+	return MulticastScope(ip[1] & 0x0f)
+}
+
+// Classify returns the categories that apply to the supplied IP. It is
+// equivalent to calling ClassifyNet() with a single-address network. If ip is
+// not a valid 4 or 16 byte address no categories are returned
+func (r *ReservationRegistry) Classify(ip net.IP) Category {
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return 0
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return r.ClassifyNet(iplib.NewNet(ip4, 32))
+	}
+	return r.ClassifyNet(iplib.NewNet(ip, 128))
+}
+
+// ClassifyNet returns the categories that apply to every address in the
+// supplied network. Categories come from the reservations containing the
+// network, plus CategoryMulticast if MulticastRegistry has a block containing
+// it. The special-purpose registries only cover unicast space, so 224.0.0.0/4
+// and ff00::/8 are not otherwise reserved. A network
+// that is not multicast is CategoryGlobalUnicast if the most specific
+// reservation containing it, if there is one, is globally reachable and no
+// reservation inside it is not
+func (r *ReservationRegistry) ClassifyNet(n iplib.Net) Category {
+	var c Category
+	for _, res := range MulticastRegistry.GetReservationsForNetwork(n) {
+		if res.Network.ContainsNet(n) {
+			c |= CategoryMulticast
+			break
+		}
+	}
+
+	global, parent := true, false
+	for _, res := range r.GetReservationsForNetwork(n) {
+		if !res.Network.ContainsNet(n) {
+			global = global && res.Global
+			continue
+		}
+		c |= reservationCategory(res)
+		if !parent {
+			global = global && res.Global
+			parent = true
+		}
+	}
+
+	if global && !c.Has(CategoryMulticast) {
+		c |= CategoryGlobalUnicast
+	}
+	return c
+}
+
+// reservationCategory returns the categories matched by res
+func reservationCategory(res *Reservation) Category {
+	var c Category
+	title := strings.ToLower(res.Title)
+	for _, rule := range categoryRules {
+		if rule.title != "" && !strings.Contains(title, rule.title) {
+			continue
+		}
+		for _, rfc := range res.RFC {
+			if rfc == rule.rfc {
+				c |= rule.cat
+				break
+			}
+		}
+	}
+	return c
+}
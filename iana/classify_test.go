@@ -0,0 +1,104 @@
+package iana
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var ClassifyTests = []struct {
+	name    string
+	address string
+	cat     Category
+	scope   MulticastScope
+}{
+	{"Unspecifiedv4", "0.0.0.0", CategoryUnspecified, ScopeNone},
+	{"Loopbackv4", "127.0.0.1", CategoryLoopback, ScopeNone},
+	{"Privatev4", "172.20.1.1", CategoryPrivate, ScopeNone},
+	{"SharedCGNv4", "100.64.12.1", CategorySharedCGN, ScopeNone},
+	{"LinkLocalv4", "169.254.1.1", CategoryLinkLocal, ScopeNone},
+	{"Documentationv4", "198.51.100.7", CategoryDocumentation, ScopeNone},
+	{"Benchmarkingv4", "198.19.0.1", CategoryBenchmarking, ScopeNone},
+	{"Broadcastv4", "255.255.255.255", CategoryBroadcast, ScopeNone},
+	{"GlobalUnicastv4", "8.8.8.8", CategoryGlobalUnicast, ScopeNone},
+	{"AnycastGlobalv4", "192.0.0.9", CategoryGlobalUnicast, ScopeNone},
+	{"IETFAssignmentv4", "192.0.0.100", 0, ScopeNone},
+	{"MulticastLinkLocalv4", "224.0.0.251", CategoryMulticast, ScopeLinkLocal},
+	{"MulticastGlobalv4", "233.252.0.1", CategoryMulticast, ScopeGlobal},
+	{"MulticastOrgLocalv4", "239.193.1.1", CategoryMulticast, ScopeOrganizationLocal},
+	{"MulticastSiteLocalv4", "239.255.255.250", CategoryMulticast, ScopeSiteLocal},
+	{"MulticastAdminLocalv4", "239.1.2.3", CategoryMulticast, ScopeAdminLocal},
+	{"Mappedv4", "::ffff:10.1.2.3", CategoryPrivate, ScopeNone},
+
+	{"Unspecifiedv6", "::", CategoryUnspecified, ScopeNone},
+	{"Loopbackv6", "::1", CategoryLoopback, ScopeNone},
+	{"LinkLocalv6", "fe80::1", CategoryLinkLocal, ScopeNone},
+	{"ULAv6", "fd12:3456::1", CategoryULA, ScopeNone},
+	{"Documentationv6", "2001:db8::1", CategoryDocumentation, ScopeNone},
+	{"Documentation3fffv6", "3fff:1::1", CategoryDocumentation, ScopeNone},
+	{"Benchmarkingv6", "2001:2::1", CategoryBenchmarking, ScopeNone},
+	{"Teredov6", "2001:0:4136:e378::1", CategoryGlobalUnicast, ScopeNone},
+	{"GlobalUnicastv6", "2600::1", CategoryGlobalUnicast, ScopeNone},
+	{"MulticastLinkLocalv6", "ff02::1", CategoryMulticast, ScopeLinkLocal},
+	{"MulticastSiteLocalv6", "ff05::1:3", CategoryMulticast, ScopeSiteLocal},
+	{"MulticastGlobalv6", "ff3e::8000:1", CategoryMulticast, ScopeGlobal},
+}
+
+func TestClassify(t *testing.T) {
+	for _, tt := range ClassifyTests {
+		ip := net.ParseIP(tt.address)
+		c := Classify(ip)
+		if c != tt.cat {
+			t.Errorf("'%s' expected categories '%s', got '%s'", tt.name, tt.cat, c)
+		}
+		if s := GetMulticastScope(ip); s != tt.scope {
+			t.Errorf("'%s' expected scope %s, got %s", tt.name, tt.scope, s)
+		}
+	}
+
+	for _, ip := range []net.IP{nil, {}, {10, 0, 0}, {0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0}} {
+		if c := Classify(ip); c != 0 {
+			t.Errorf("expected no categories for invalid IP %#v, got '%s'", ip, c)
+		}
+	}
+}
+
+var ClassifyNetTests = []struct {
+	name    string
+	network string
+	cat     Category
+}{
+	{"Privatev4", "10.0.0.0/8", CategoryPrivate},
+	{"InsidePrivatev4", "10.20.0.0/16", CategoryPrivate},
+	{"ContainsPrivatev4", "8.0.0.0/6", 0},
+	{"GlobalUnicastv4", "1.0.0.0/8", CategoryGlobalUnicast},
+	{"Multicastv4", "224.0.0.0/4", CategoryMulticast},
+	{"Multicastv6", "ff00::/8", CategoryMulticast},
+	{"PartlyMulticastv6", "f000::/4", 0},
+	{"ULAv6", "fd00::/8", CategoryULA},
+	{"Documentationv6", "2001:db8:1::/48", CategoryDocumentation},
+	{"GlobalUnicastv6", "2600::/12", CategoryGlobalUnicast},
+}
+
+func TestClassifyNet(t *testing.T) {
+	for _, tt := range ClassifyNetTests {
+		_, n, _ := iplib.ParseCIDR(tt.network)
+		if c := ClassifyNet(n); c != tt.cat {
+			t.Errorf("'%s' expected categories '%s', got '%s'", tt.name, tt.cat, c)
+		}
+	}
+}
+
+func TestCategory_Has(t *testing.T) {
+	c := CategoryPrivate | CategoryGlobalUnicast
+	if !c.Has(CategoryPrivate) || !c.Has(CategoryPrivate|CategoryGlobalUnicast) {
+		t.Error("expected Has to find categories in the set")
+	}
+	if c.Has(CategoryPrivate | CategoryULA) {
+		t.Error("expected Has to fail for a category missing from the set")
+	}
+	if s := c.String(); s != "private|global-unicast" {
+		t.Errorf("expected 'private|global-unicast', got '%s'", s)
+	}
+}
@@ -21,12 +21,22 @@ package iana
 import (
 	"github.com/kenits/iplib"
 	"net"
-	"sort"
+	"time"
 )
 
-// Registry holds the aggregated network list from IANA's v4 and v6 registries.
-// Only the following fields were imported: Address Block, Name, RFC,
-// Forwardable, Globally Reachable and Reserved-by-Protocol
+// DefaultRegistry holds the aggregated network list from IANA's v4 and v6
+// registries and is used by all of the package-level functions. The
+// following fields were imported: Address Block, Name, RFC, Allocation Date,
+// Termination Date, Source, Destination, Forwardable, Globally Reachable and
+// Reserved-by-Protocol
+var DefaultRegistry *ReservationRegistry
+
+// Registry holds the reservations DefaultRegistry was built from.
+//
+// Deprecated: use DefaultRegistry, or a ReservationRegistry of your own.
+// Changing a reservation in Registry changes it in DefaultRegistry, but
+// entries added to or removed from the slice are not seen by the package
+// functions; use DefaultRegistry.Merge() and DefaultRegistry.Remove()
 var Registry []*Reservation
 
 // Reservation describes an entry in the IANA IP Special Registry
@@ -52,153 +62,179 @@ type Reservation struct {
 	// true if an IP implementation must implement this policy in order to
 	// be compliant
 	Reserved bool
+
+	// true if an address from this network is valid when used as the source
+	// address of an IP datagram
+	Source bool
+
+	// true if an address from this network is valid when used as the
+	// destination address of an IP datagram
+	Destination bool
+
+	// AllocationDate is the date the reservation was made, IANA publishes
+	// these at the resolution of a month
+	AllocationDate time.Time
+
+	// TerminationDate is the date the reservation was withdrawn, or is
+	// scheduled to be withdrawn. It is the zero time if no such date exists
+	TerminationDate time.Time
 }
 
 func init() {
-	Registry = []*Reservation{
-		{getFromCIDR("0.0.0.0/8"), "This host on this network", []string{"RFC1122"}, false, false, true},
-		{getFromCIDR("10.0.0.0/8"), "Private-Use", []string{"RFC1918"}, true, false, false},
-		{getFromCIDR("100.64.0.0/10"), "Shared Address Space", []string{"RFC6598"}, false, false, true},
-		{getFromCIDR("127.0.0.0/8"), "Loopback", []string{"RFC1122"}, false, false, true},
-		{getFromCIDR("169.254.0.0/16"), "Link Local", []string{"RFC3927"}, false, false, true},
-		{getFromCIDR("172.16.0.0/12"), "Private-Use", []string{"RFC1918"}, true, false, false},
-		{getFromCIDR("192.0.0.0/24"), "IETF Protocol Assignments", []string{"RFC6890"}, false, false, false},
-		{getFromCIDR("192.0.0.0/29"), "IPv4 Service Continuity Prefix", []string{"RFC7335"}, true, false, false},
-		{getFromCIDR("192.0.0.8/32"), "IPv4 dummy address", []string{"RFC7600"}, false, false, false},
-		{getFromCIDR("192.0.0.9/32"), "Port Control Protocol Anycast", []string{"RFC7723"}, true, true, true},
-		{getFromCIDR("192.0.0.10/32"), "Traversal Using Relays around NAT Anycast", []string{"RFC8155"}, true, true, false},
-		{getFromCIDR("192.0.0.170/32"), "NAT64/DNS64 Discovery", []string{"RFC7050"}, false, false, true},
-		{getFromCIDR("192.0.0.171/32"), "NAT64/DNS64 Discovery", []string{"RFC7050"}, false, false, true},
-		{getFromCIDR("192.0.2.0/24"), "Documentation (TEST-NET-1)", []string{"RFC5737"}, false, false, false},
-		{getFromCIDR("192.31.196.0/24"), "AS112-v4", []string{"RFC7535"}, true, true, false},
-		{getFromCIDR("192.52.193.0/24"), "AMT", []string{"RFC7450"}, true, true, false},
-		{getFromCIDR("192.168.0.0/16"), "Private-Use", []string{"RFC1918"}, true, false, false},
-		{getFromCIDR("192.175.48.0/24"), "Direct Delegation AS112 Service", []string{"RFC7534"}, true, true, false},
-		{getFromCIDR("198.18.0.0/15"), "Benchmarking", []string{"RFC2544"}, true, false, false},
-		{getFromCIDR("198.51.100.0/24"), "Documentation (TEST-NET-2)", []string{"RFC5737"}, false, false, false},
-		{getFromCIDR("203.0.113.0/24"), "Documentation (TEST-NET-3)", []string{"RFC5737"}, false, false, false},
-		{getFromCIDR("240.0.0.0/4"), "Reserved", []string{"RFC1112"}, false, false, true},
-		{getFromCIDR("255.255.255.255/32"), "Limited Broadcast", []string{"RFC8190", "RFC919"}, false, false, true},
-		{getFromCIDR("::1/128"), "Loopback Address", []string{"RFC4291"}, false, false, true},
-		{getFromCIDR("::/128"), "Unspecified Address", []string{"RFC4291"}, false, false, true},
-		{getFromCIDR("::ffff:0:0/96"), "IPv4-mapped Address", []string{"RFC4291"}, false, false, true},
-		{getFromCIDR("64:ff9b::/96"), "IPv4-IPv6 Translation", []string{"RFC6052"}, true, true, false},
-		{getFromCIDR("64:ff9b:1::/48"), "IPv4-IPv6 Translation", []string{"RFC8215"}, true, false, false},
-		{getFromCIDR("100::/64"), "Discard-Only Address Block", []string{"RFC6666"}, true, false, false},
-		{getFromCIDR("2001::/23"), "IETF Protocol Assignments", []string{"RFC2928"}, false, false, false},
-		{getFromCIDR("2001::/32"), "TEREDO", []string{"RFC4380", "RFC8190"}, true, true, false},
-		{getFromCIDR("2001:1::1/128"), "Port Control Protocol Anycast", []string{"RFC7723"}, true, true, false},
-		{getFromCIDR("2001:1::2/128"), "Traversal Using Relays around NAT Anycast", []string{"RFC8155"}, true, true, false},
-		{getFromCIDR("2001:2::/48"), "Benchmarking", []string{"RFC5180", "RFC1752"}, true, false, false},
-		{getFromCIDR("2001:3::/32"), "AMT", []string{"RFC7450"}, true, true, false},
-		{getFromCIDR("2001:4:112::/48"), "AS112-v6", []string{"RFC7535"}, true, true, false},
-		{getFromCIDR("2001:5::/32"), "EID Space for LISP (Managed by RIPE NCC)", []string{"RFC7954"}, true, true, true},
-		{getFromCIDR("2001:20::/28"), "ORCHIDv2", []string{"RFC7343"}, true, true, false},
-		{getFromCIDR("2001:db8::/32"), "Documentation", []string{"RFC3849"}, false, false, false},
-		{getFromCIDR("2002::/16"), "6to4", []string{"RFC3056"}, true, true, false},
-		{getFromCIDR("2620:4f:8000::/48"), "Direct Delegation AS112 Service", []string{"RFC7534"}, true, true, false},
-		{getFromCIDR("fc00::/7"), "Unique-Local", []string{"RFC4193", "RFC8190"}, true, false, false},
-		{getFromCIDR("fe80::/10"), "Link-Local Unicast", []string{"RFC4291"}, false, false, true},
-	}
+	DefaultRegistry = NewRegistry([]*Reservation{
+		{getFromCIDR("0.0.0.0/8"), "This network", []string{"RFC791"}, false, false, true, true, false, getDate("1981-09"), time.Time{}},
+		{getFromCIDR("0.0.0.0/32"), "This host on this network", []string{"RFC1122"}, false, false, true, true, false, getDate("1981-09"), time.Time{}},
+		{getFromCIDR("10.0.0.0/8"), "Private-Use", []string{"RFC1918"}, true, false, false, true, true, getDate("1996-02"), time.Time{}},
+		{getFromCIDR("100.64.0.0/10"), "Shared Address Space", []string{"RFC6598"}, true, false, false, true, true, getDate("2012-04"), time.Time{}},
+		{getFromCIDR("127.0.0.0/8"), "Loopback", []string{"RFC1122"}, false, false, true, false, false, getDate("1981-09"), time.Time{}},
+		{getFromCIDR("169.254.0.0/16"), "Link Local", []string{"RFC3927"}, false, false, true, true, true, getDate("2005-05"), time.Time{}},
+		{getFromCIDR("172.16.0.0/12"), "Private-Use", []string{"RFC1918"}, true, false, false, true, true, getDate("1996-02"), time.Time{}},
+		{getFromCIDR("192.0.0.0/24"), "IETF Protocol Assignments", []string{"RFC6890"}, false, false, false, false, false, getDate("2010-01"), time.Time{}},
+		{getFromCIDR("192.0.0.0/29"), "IPv4 Service Continuity Prefix", []string{"RFC7335"}, true, false, false, true, true, getDate("2011-06"), time.Time{}},
+		{getFromCIDR("192.0.0.8/32"), "IPv4 dummy address", []string{"RFC7600"}, false, false, false, true, false, getDate("2015-03"), time.Time{}},
+		{getFromCIDR("192.0.0.9/32"), "Port Control Protocol Anycast", []string{"RFC7723"}, true, true, false, true, true, getDate("2015-10"), time.Time{}},
+		{getFromCIDR("192.0.0.10/32"), "Traversal Using Relays around NAT Anycast", []string{"RFC8155"}, true, true, false, true, true, getDate("2017-02"), time.Time{}},
+		{getFromCIDR("192.0.0.170/32"), "NAT64/DNS64 Discovery", []string{"RFC8880", "RFC7050"}, false, false, true, false, false, getDate("2013-02"), time.Time{}},
+		{getFromCIDR("192.0.0.171/32"), "NAT64/DNS64 Discovery", []string{"RFC8880", "RFC7050"}, false, false, true, false, false, getDate("2013-02"), time.Time{}},
+		{getFromCIDR("192.0.2.0/24"), "Documentation (TEST-NET-1)", []string{"RFC5737"}, false, false, false, false, false, getDate("2010-01"), time.Time{}},
+		{getFromCIDR("192.31.196.0/24"), "AS112-v4", []string{"RFC7535"}, true, true, false, true, true, getDate("2014-12"), time.Time{}},
+		{getFromCIDR("192.52.193.0/24"), "AMT", []string{"RFC7450"}, true, true, false, true, true, getDate("2014-12"), time.Time{}},
+		{getFromCIDR("192.88.99.2/32"), "6a44-relay anycast address", []string{"RFC6751"}, true, false, false, true, true, getDate("2012-10"), time.Time{}},
+		{getFromCIDR("192.168.0.0/16"), "Private-Use", []string{"RFC1918"}, true, false, false, true, true, getDate("1996-02"), time.Time{}},
+		{getFromCIDR("192.175.48.0/24"), "Direct Delegation AS112 Service", []string{"RFC7534"}, true, true, false, true, true, getDate("1996-01"), time.Time{}},
+		{getFromCIDR("198.18.0.0/15"), "Benchmarking", []string{"RFC2544"}, true, false, false, true, true, getDate("1999-03"), time.Time{}},
+		{getFromCIDR("198.51.100.0/24"), "Documentation (TEST-NET-2)", []string{"RFC5737"}, false, false, false, false, false, getDate("2010-01"), time.Time{}},
+		{getFromCIDR("203.0.113.0/24"), "Documentation (TEST-NET-3)", []string{"RFC5737"}, false, false, false, false, false, getDate("2010-01"), time.Time{}},
+		{getFromCIDR("240.0.0.0/4"), "Reserved", []string{"RFC1112"}, false, false, true, false, false, getDate("1989-08"), time.Time{}},
+		{getFromCIDR("255.255.255.255/32"), "Limited Broadcast", []string{"RFC8190", "RFC919"}, false, false, true, false, true, getDate("1984-10"), time.Time{}},
+		{getFromCIDR("::1/128"), "Loopback Address", []string{"RFC4291"}, false, false, true, false, false, getDate("2006-02"), time.Time{}},
+		{getFromCIDR("::/128"), "Unspecified Address", []string{"RFC4291"}, false, false, true, true, false, getDate("2006-02"), time.Time{}},
+		{getFromCIDR("::ffff:0:0/96"), "IPv4-mapped Address", []string{"RFC4291"}, false, false, true, false, false, getDate("2006-02"), time.Time{}},
+		{getFromCIDR("64:ff9b::/96"), "IPv4-IPv6 Translation", []string{"RFC6052"}, true, true, false, true, true, getDate("2010-10"), time.Time{}},
+		{getFromCIDR("64:ff9b:1::/48"), "IPv4-IPv6 Translation", []string{"RFC8215"}, true, false, false, true, true, getDate("2017-06"), time.Time{}},
+		{getFromCIDR("100::/64"), "Discard-Only Address Block", []string{"RFC6666"}, true, false, false, true, true, getDate("2012-06"), time.Time{}},
+		{getFromCIDR("100:0:0:1::/64"), "Dummy IPv6 Prefix", []string{"RFC9780"}, false, false, false, true, false, getDate("2025-04"), time.Time{}},
+		{getFromCIDR("2001::/23"), "IETF Protocol Assignments", []string{"RFC2928"}, false, false, false, false, false, getDate("2000-09"), time.Time{}},
+		{getFromCIDR("2001::/32"), "TEREDO", []string{"RFC4380", "RFC8190"}, true, true, false, true, true, getDate("2006-01"), time.Time{}},
+		{getFromCIDR("2001:1::1/128"), "Port Control Protocol Anycast", []string{"RFC7723"}, true, true, false, true, true, getDate("2015-10"), time.Time{}},
+		{getFromCIDR("2001:1::2/128"), "Traversal Using Relays around NAT Anycast", []string{"RFC8155"}, true, true, false, true, true, getDate("2017-02"), time.Time{}},
+		{getFromCIDR("2001:1::3/128"), "DNS-SD Service Registration Protocol Anycast Address", []string{"RFC9665"}, true, true, false, true, true, getDate("2024-04"), time.Time{}},
+		{getFromCIDR("2001:2::/48"), "Benchmarking", []string{"RFC5180"}, true, false, false, true, true, getDate("2008-04"), time.Time{}},
+		{getFromCIDR("2001:3::/32"), "AMT", []string{"RFC7450"}, true, true, false, true, true, getDate("2014-12"), time.Time{}},
+		{getFromCIDR("2001:4:112::/48"), "AS112-v6", []string{"RFC7535"}, true, true, false, true, true, getDate("2014-12"), time.Time{}},
+		{getFromCIDR("2001:5::/32"), "EID Space for LISP (Managed by RIPE NCC)", []string{"RFC7954"}, true, true, true, true, true, getDate("2016-09"), time.Time{}},
+		{getFromCIDR("2001:20::/28"), "ORCHIDv2", []string{"RFC7343"}, true, true, false, true, true, getDate("2014-07"), time.Time{}},
+		{getFromCIDR("2001:30::/28"), "Drone Remote ID Protocol Entity Tags (DETs) Prefix", []string{"RFC9374"}, true, true, false, true, true, getDate("2022-12"), time.Time{}},
+		{getFromCIDR("2001:db8::/32"), "Documentation", []string{"RFC3849"}, false, false, false, false, false, getDate("2004-07"), time.Time{}},
+		{getFromCIDR("2002::/16"), "6to4", []string{"RFC3056"}, true, true, false, true, true, getDate("2001-02"), time.Time{}},
+		{getFromCIDR("2620:4f:8000::/48"), "Direct Delegation AS112 Service", []string{"RFC7534"}, true, true, false, true, true, getDate("2011-05"), time.Time{}},
+		{getFromCIDR("3fff::/20"), "Documentation", []string{"RFC9637"}, false, false, false, false, false, getDate("2024-07"), time.Time{}},
+		{getFromCIDR("5f00::/16"), "Segment Routing (SRv6) SIDs", []string{"RFC9602"}, true, false, false, true, true, getDate("2024-04"), time.Time{}},
+		{getFromCIDR("fc00::/7"), "Unique-Local", []string{"RFC4193", "RFC8190"}, true, false, false, true, true, getDate("2005-10"), time.Time{}},
+		{getFromCIDR("fe80::/10"), "Link-Local Unicast", []string{"RFC4291"}, false, false, true, true, true, getDate("2006-02"), time.Time{}},
+	})
+	Registry = DefaultRegistry.Reservations()
+}
+
+// Bogons returns an aggregated list of the networks of the given IP version
+// that should never be seen on the public Internet, optionally including the
+// supplied unallocated space. It queries DefaultRegistry, see
+// ReservationRegistry.Bogons() for details
+func Bogons(version int, unallocated ...iplib.Net) []iplib.Net {
+	return DefaultRegistry.Bogons(version, unallocated...)
+}
+
+// Classify returns the categories that apply to the supplied IP. It queries
+// DefaultRegistry, see ReservationRegistry.Classify() for details
+func Classify(ip net.IP) Category {
+	return DefaultRegistry.Classify(ip)
+}
+
+// ClassifyNet returns the categories that apply to every address in the
+// supplied network. It queries DefaultRegistry, see
+// ReservationRegistry.ClassifyNet() for details
+func ClassifyNet(n iplib.Net) Category {
+	return DefaultRegistry.ClassifyNet(n)
 }
 
 // GetReservationsForNetwork returns a list of any IANA reserved networks
 // that are either part of the supplied network or that the supplied network
-// is part of
+// is part of, ordered from most to least specific. It queries DefaultRegistry
 func GetReservationsForNetwork(n iplib.Net) []*Reservation {
-	reservations := []*Reservation{}
-	for _, r := range Registry {
-		if r.Network.ContainsNet(n) || n.ContainsNet(r.Network) {
-			reservations = append(reservations, r)
-		}
-	}
-	return reservations
+	return DefaultRegistry.GetReservationsForNetwork(n)
 }
 
 // GetReservationsForIP returns a list of any IANA reserved networks that
-// the supplied IP is part of
+// the supplied IP is part of, ordered from most to least specific. It queries
+// DefaultRegistry
 func GetReservationsForIP(ip net.IP) []*Reservation {
-	reservations := []*Reservation{}
-	for _, r := range Registry {
-		if r.Network.Contains(ip) {
-			if iplib.EffectiveVersion(ip) == 4 && r.Title == "IPv4-mapped Address" {
-				continue
-			}
-			reservations = append(reservations, r)
-		}
-	}
-	return reservations
+	return DefaultRegistry.GetReservationsForIP(ip)
 }
 
 // GetRFCsForNetwork returns a list of all RFCs that apply to the given
-// network
+// network. It queries DefaultRegistry
 func GetRFCsForNetwork(n iplib.Net) []string {
-	rfclist := []string{}
-	reservations := GetReservationsForNetwork(n)
-	if len(reservations) > 0 {
-		for _, r := range reservations {
-		LOOP:
-			for _, rfc := range r.RFC {
-				for _, xrfc := range rfclist {
-					if xrfc == rfc {
-						continue LOOP
-					}
-				}
-				rfclist = append(rfclist, rfc)
-			}
-		}
-		sort.Strings(rfclist)
-	}
-	return rfclist
+	return DefaultRegistry.GetRFCsForNetwork(n)
 }
 
 // IsForwardable will return false if the given iplib.Net contains or is
-// contained in a network that is marked not-forwardable in the IANA registry.
-// IANA defines a forwardable network as one where "...a router may forward an
-// IP datagram whose destination address is drawn from the allocated special-
-// purpose address block between external interfaces." The default is 'true'
+// contained in a network that is marked not-forwardable in DefaultRegistry.
+// See ReservationRegistry.IsForwardable() for details
 func IsForwardable(n iplib.Net) bool {
-	reservations := GetReservationsForNetwork(n)
-	for _, r := range reservations {
-		if r.Forwardable == false {
-			return false
-		}
-	}
-	return true
+	return DefaultRegistry.IsForwardable(n)
 }
 
 // IsGlobal will return false if the given iplib.Net contains or is contained
-// in a network that is marked not-global in the IANA registry. IANA defines a
-// global network as one where "...an IP datagram whose destination address is
-// drawn from the allocated special-purpose address block is forwardable
-// beyond a specified administrative domain." The default is 'true'
+// in a network that is marked not-global in DefaultRegistry. See
+// ReservationRegistry.IsGlobal() for details
 func IsGlobal(n iplib.Net) bool {
-	reservations := GetReservationsForNetwork(n)
-	for _, r := range reservations {
-		if r.Global == false {
-			return false
-		}
-	}
-	return true
-}
-
-// IsReserved  will return true if the given iplib.Net contains or is
-// contained in a network that is marked reserved-by-protocol in the IANA
-// registry. IANA defines a reserved network as one where "...the RFC that
-// created the special-purpose address block requires all compliant IP
-// implementations to behave in a special way when processing packets either
-// to or from addresses contained by the address block." The default is 'false'
+	return DefaultRegistry.IsGlobal(n)
+}
+
+// IsReserved will return true if the given iplib.Net contains or is
+// contained in a network that is marked reserved-by-protocol in
+// DefaultRegistry. See ReservationRegistry.IsReserved() for details
 func IsReserved(n iplib.Net) bool {
-	reservations := GetReservationsForNetwork(n)
-	for _, r := range reservations {
-		if r.Reserved == true {
-			return true
-		}
-	}
-	return false
+	return DefaultRegistry.IsReserved(n)
+}
+
+// IsValidDestination will return false if DefaultRegistry says addresses in
+// the given iplib.Net may not be used as the destination of a datagram. See
+// ReservationRegistry.IsValidDestination() for details
+func IsValidDestination(n iplib.Net) bool {
+	return DefaultRegistry.IsValidDestination(n)
+}
+
+// IsValidSource will return false if DefaultRegistry says addresses in the
+// given iplib.Net may not be used as the source of a datagram. See
+// ReservationRegistry.IsValidSource() for details
+func IsValidSource(n iplib.Net) bool {
+	return DefaultRegistry.IsValidSource(n)
+}
+
+// Martians returns an aggregated list of the networks of the given IP
+// version that are not globally reachable or are reserved by protocol. It
+// queries DefaultRegistry, see ReservationRegistry.Martians() for details
+func Martians(version int) []iplib.Net {
+	return DefaultRegistry.Martians(version)
+}
+
+// copy returns a deep copy of res
+func (res *Reservation) copy() *Reservation {
+	xres := *res
+	xres.Network.IP = copyBytes(res.Network.IP)
+	xres.Network.Mask = copyBytes(res.Network.Mask)
+	xres.RFC = make([]string, len(res.RFC))
+	copy(xres.RFC, res.RFC)
+	return &xres
 }
 
 func getFromCIDR(s string) iplib.Net {
 	_, n, _ := iplib.ParseCIDR(s)
 	return n
 }
+
+func getDate(s string) time.Time {
+	t, _ := time.Parse("2006-01", s)
+	return t
+}
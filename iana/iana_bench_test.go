@@ -0,0 +1,126 @@
+package iana
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+// originalReservationsForIP is the scan-based lookup the index replaced, kept
+// as a baseline to benchmark against
+func originalReservationsForIP(reservations []*Reservation, ip net.IP) []*Reservation {
+	res := []*Reservation{}
+	for _, r := range reservations {
+		if r.Network.Contains(ip) {
+			if iplib.EffectiveVersion(ip) == 4 && r.Title == "IPv4-mapped Address" {
+				continue
+			}
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// originalReservationsForNetwork is the scan-based lookup the index
+// replaced, kept as a baseline to benchmark against
+func originalReservationsForNetwork(reservations []*Reservation, n iplib.Net) []*Reservation {
+	res := []*Reservation{}
+	for _, r := range reservations {
+		if r.Network.ContainsNet(n) || n.ContainsNet(r.Network) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// linearReservationsForIP is a scan returning the same reservations as the
+// index, which unlike the original lookup never compares a v4 address with a
+// v6 reservation. TestRegistry_IndexMatchesScan checks the index against it
+func linearReservationsForIP(reservations []*Reservation, ip net.IP) []*Reservation {
+	res := []*Reservation{}
+	for _, r := range reservations {
+		if r.Network.Version() != iplib.EffectiveVersion(ip) {
+			continue
+		}
+		if r.Network.Contains(ip) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// linearReservationsForNetwork is a scan returning the same reservations as
+// the index, see linearReservationsForIP()
+func linearReservationsForNetwork(reservations []*Reservation, n iplib.Net) []*Reservation {
+	res := []*Reservation{}
+	for _, r := range reservations {
+		if r.Network.Version() != n.Version() {
+			continue
+		}
+		if r.Network.ContainsNet(n) || n.ContainsNet(r.Network) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func BenchmarkGetReservationsForIP4(b *testing.B) {
+	ip := net.ParseIP("192.168.23.5")
+	for i := 0; i < b.N; i++ {
+		DefaultRegistry.GetReservationsForIP(ip)
+	}
+}
+
+func BenchmarkGetReservationsForIP4_Linear(b *testing.B) {
+	ip := net.ParseIP("192.168.23.5")
+	res := DefaultRegistry.Reservations()
+	for i := 0; i < b.N; i++ {
+		originalReservationsForIP(res, ip)
+	}
+}
+
+func BenchmarkGetReservationsForIP6(b *testing.B) {
+	ip := net.ParseIP("2001:db8:1::250:3")
+	for i := 0; i < b.N; i++ {
+		DefaultRegistry.GetReservationsForIP(ip)
+	}
+}
+
+func BenchmarkGetReservationsForIP6_Linear(b *testing.B) {
+	ip := net.ParseIP("2001:db8:1::250:3")
+	res := DefaultRegistry.Reservations()
+	for i := 0; i < b.N; i++ {
+		originalReservationsForIP(res, ip)
+	}
+}
+
+func BenchmarkGetReservationsForNetwork4(b *testing.B) {
+	_, n, _ := iplib.ParseCIDR("192.0.0.0/12")
+	for i := 0; i < b.N; i++ {
+		DefaultRegistry.GetReservationsForNetwork(n)
+	}
+}
+
+func BenchmarkGetReservationsForNetwork4_Linear(b *testing.B) {
+	_, n, _ := iplib.ParseCIDR("192.0.0.0/12")
+	res := DefaultRegistry.Reservations()
+	for i := 0; i < b.N; i++ {
+		originalReservationsForNetwork(res, n)
+	}
+}
+
+func BenchmarkGetReservationsForNetwork6(b *testing.B) {
+	_, n, _ := iplib.ParseCIDR("2001::/16")
+	for i := 0; i < b.N; i++ {
+		DefaultRegistry.GetReservationsForNetwork(n)
+	}
+}
+
+func BenchmarkGetReservationsForNetwork6_Linear(b *testing.B) {
+	_, n, _ := iplib.ParseCIDR("2001::/16")
+	res := DefaultRegistry.Reservations()
+	for i := 0; i < b.N; i++ {
+		originalReservationsForNetwork(res, n)
+	}
+}
@@ -95,7 +95,7 @@ var NetTests = []struct {
 		"MultipleReservationsv4",
 		8,
 		"192.0.0.0/12",
-		[]string{"RFC5737", "RFC6890", "RFC7050", "RFC7335", "RFC7600", "RFC7723", "RFC8155"},
+		[]string{"RFC5737", "RFC6890", "RFC7050", "RFC7335", "RFC7600", "RFC7723", "RFC8155", "RFC8880"},
 		false,
 		false,
 		true,
@@ -139,9 +139,9 @@ var NetTests = []struct {
 	},
 	{
 		"MultipleReservationsv6",
-		10,
+		12,
 		"2001::/16",
-		[]string{"RFC1752", "RFC2928", "RFC3849", "RFC4380", "RFC5180", "RFC7343", "RFC7450", "RFC7535", "RFC7723", "RFC7954", "RFC8155", "RFC8190"},
+		[]string{"RFC2928", "RFC3849", "RFC4380", "RFC5180", "RFC7343", "RFC7450", "RFC7535", "RFC7723", "RFC7954", "RFC8155", "RFC8190", "RFC9374", "RFC9665"},
 		false,
 		false,
 		true,
@@ -198,6 +198,91 @@ func TestIsReserved(t *testing.T) {
 	}
 }
 
+var SourceDestinationTests = []struct {
+	network     string
+	source      bool
+	destination bool
+}{
+	{
+		"1.0.0.0/8",
+		true,
+		true,
+	},
+	{
+		"0.0.0.0/32",
+		true,
+		false,
+	},
+	{
+		"127.0.0.1/32",
+		false,
+		false,
+	},
+	{
+		"255.255.255.255/32",
+		false,
+		true,
+	},
+	{
+		"192.0.0.0/24",
+		false,
+		false,
+	},
+	{
+		"192.0.0.9/32",
+		true,
+		true,
+	},
+	{
+		"192.0.0.8/32",
+		true,
+		false,
+	},
+	{
+		"::/128",
+		true,
+		false,
+	},
+	{
+		"2001::/23",
+		false,
+		false,
+	},
+	{
+		"2001:0:4136:e378::/64",
+		true,
+		true,
+	},
+	{
+		"2001:2::/48",
+		true,
+		true,
+	},
+	{
+		"2001:db8::1/128",
+		false,
+		false,
+	},
+}
+
+func TestIsValidSource(t *testing.T) {
+	for _, tt := range SourceDestinationTests {
+		_, n, _ := iplib.ParseCIDR(tt.network)
+		if v := IsValidSource(n); v != tt.source {
+			t.Errorf("(%s) expected %t, got %t", tt.network, tt.source, v)
+		}
+	}
+}
+
+func TestIsValidDestination(t *testing.T) {
+	for _, tt := range SourceDestinationTests {
+		_, n, _ := iplib.ParseCIDR(tt.network)
+		if v := IsValidDestination(n); v != tt.destination {
+			t.Errorf("(%s) expected %t, got %t", tt.network, tt.destination, v)
+		}
+	}
+}
+
 func equalList(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
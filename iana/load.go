@@ -0,0 +1,271 @@
+package iana
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	ErrMissingColumn = errors.New("registry CSV is missing a required column")
+	ErrUnknownFormat = errors.New("registry file is neither XML nor CSV")
+)
+
+var (
+	footnoteRE = regexp.MustCompile(`\[\d+\]`)
+	rfcRE      = regexp.MustCompile(`(?i)RFC\s*(\d+)`)
+)
+
+// csvColumns are the column headers, as published by IANA, needed to build
+// a Reservation
+var csvColumns = []string{
+	"Address Block",
+	"Name",
+	"RFC",
+	"Allocation Date",
+	"Termination Date",
+	"Source",
+	"Destination",
+	"Forwardable",
+	"Globally Reachable",
+	"Reserved-by-Protocol",
+}
+
+type xmlRecord struct {
+	Address     string  `xml:"address"`
+	Name        string  `xml:"name"`
+	Spec        xmlSpec `xml:"spec"`
+	Allocation  string  `xml:"allocation"`
+	Termination string  `xml:"termination"`
+	Source      string  `xml:"source"`
+	Destination string  `xml:"destination"`
+	Forwardable string  `xml:"forwardable"`
+	Global      string  `xml:"global"`
+	Reserved    string  `xml:"reserved"`
+}
+
+type xmlSpec struct {
+	Xrefs []struct {
+		Type string `xml:"type,attr"`
+		Data string `xml:"data,attr"`
+	} `xml:"xref"`
+	Text string `xml:",chardata"`
+}
+
+// LoadFile reads an IANA special-purpose address registry from the named
+// file, which must be either the XML or CSV version of the
+// iana-ipv4-special-registry or iana-ipv6-special-registry as published by
+// IANA. The format is determined from the file extension.
+func LoadFile(path string) ([]*Reservation, error) {
+	var load func(io.Reader) ([]*Reservation, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		load = LoadXML
+	case ".csv":
+		load = LoadCSV
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return load(f)
+}
+
+// LoadCSV reads the CSV version of an IANA special-purpose address registry
+// from r and returns it as a list of reservations. Columns are matched by
+// their header so their order does not matter, but all of the columns IANA
+// publishes must be present or ErrMissingColumn will be returned. Entries
+// listing multiple address blocks are split into one reservation per block.
+func LoadCSV(r io.Reader) ([]*Reservation, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make(map[string]int)
+	for i, h := range header {
+		cols[strings.TrimSpace(h)] = i
+	}
+	for _, c := range csvColumns {
+		if _, ok := cols[c]; !ok {
+			return nil, ErrMissingColumn
+		}
+	}
+
+	reservations := []*Reservation{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i := cols[name]; i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		}
+
+		rfcs := []string{}
+		for _, m := range rfcRE.FindAllStringSubmatch(field("RFC"), -1) {
+			rfcs = appendRFC(rfcs, "RFC"+m[1])
+		}
+
+		res, err := newReservations(
+			field("Address Block"), field("Name"), rfcs,
+			field("Allocation Date"), field("Termination Date"),
+			field("Source"), field("Destination"),
+			field("Forwardable"), field("Globally Reachable"), field("Reserved-by-Protocol"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, res...)
+	}
+	return reservations, nil
+}
+
+// LoadXML reads the XML version of an IANA special-purpose address registry
+// from r and returns it as a list of reservations. Entries listing multiple
+// address blocks are split into one reservation per block.
+func LoadXML(r io.Reader) ([]*Reservation, error) {
+	reservations := []*Reservation{}
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "record" {
+			continue
+		}
+
+		var rec xmlRecord
+		if err := dec.DecodeElement(&rec, &se); err != nil {
+			return nil, err
+		}
+
+		rfcs := []string{}
+		for _, x := range rec.Spec.Xrefs {
+			if strings.ToLower(x.Type) == "rfc" {
+				rfcs = appendRFC(rfcs, strings.ToUpper(x.Data))
+			}
+		}
+		for _, m := range rfcRE.FindAllStringSubmatch(rec.Spec.Text, -1) {
+			rfcs = appendRFC(rfcs, "RFC"+m[1])
+		}
+
+		res, err := newReservations(
+			rec.Address, rec.Name, rfcs,
+			rec.Allocation, rec.Termination,
+			rec.Source, rec.Destination,
+			rec.Forwardable, rec.Global, rec.Reserved,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, res...)
+	}
+	return reservations, nil
+}
+
+func appendRFC(rfcs []string, rfc string) []string {
+	for _, r := range rfcs {
+		if r == rfc {
+			return rfcs
+		}
+	}
+	return append(rfcs, rfc)
+}
+
+// newReservations builds one Reservation for each address block listed in
+// addresses, which IANA separates with commas
+func newReservations(addresses, name string, rfcs []string, allocation, termination, source, destination, forwardable, global, reserved string) ([]*Reservation, error) {
+	allocDate, err := parseDate(allocation)
+	if err != nil {
+		return nil, err
+	}
+	termDate, err := parseDate(termination)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := []*Reservation{}
+	for _, a := range strings.Split(cleanField(addresses), ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		_, n, err := iplib.ParseCIDR(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address block '%s': %s", a, err)
+		}
+		reservations = append(reservations, &Reservation{
+			Network:         n,
+			Title:           cleanField(name),
+			RFC:             rfcs,
+			Forwardable:     parseBool(forwardable),
+			Global:          parseBool(global),
+			Reserved:        parseBool(reserved),
+			Source:          parseBool(source),
+			Destination:     parseBool(destination),
+			AllocationDate:  allocDate,
+			TerminationDate: termDate,
+		})
+	}
+	return reservations, nil
+}
+
+// cleanField strips footnote references and surplus whitespace from a value
+// read from the registry
+func cleanField(s string) string {
+	s = footnoteRE.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// parseBool interprets the registry's "True", "False" and "N/A" values, any
+// of which may be followed by a footnote. Anything other than "True" is
+// false
+func parseBool(s string) bool {
+	return strings.EqualFold(cleanField(s), "true")
+}
+
+// parseDate interprets the registry's dates, which are given as either
+// YYYY-MM or YYYY-MM-DD. "N/A" and empty values produce the zero time
+func parseDate(s string) (time.Time, error) {
+	s = cleanField(s)
+	if s == "" || strings.EqualFold(s, "N/A") {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{"2006-01", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date '%s'", s)
+}
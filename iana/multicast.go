@@ -0,0 +1,213 @@
+package iana
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+// Flags found in the high nibble of the second byte of an IPv6 multicast
+// address, see MulticastAddr.Flags
+const (
+	// MulticastFlagTransient (T) is set if the address is not permanently
+	// assigned by IANA, RFC4291
+	MulticastFlagTransient = 0x1
+
+	// MulticastFlagPrefix (P) is set if the address is based on a unicast
+	// prefix, RFC3306
+	MulticastFlagPrefix = 0x2
+
+	// MulticastFlagRP (R) is set if the address has a rendezvous point
+	// embedded in it, RFC3956
+	MulticastFlagRP = 0x4
+)
+
+var (
+	ErrBadRP        = errors.New("rendezvous point is not prefix::RIID")
+	ErrNotMulticast = errors.New("address is not multicast")
+)
+
+// MulticastRegistry holds the IPv4 blocks from IANA's Multicast Address
+// Space registry, along with the RFC2365 administratively scoped sub-blocks.
+// Unassigned and "RESERVED" ranges are not included, but the whole of the v4
+// and v6 multicast space, 224.0.0.0/4 and ff00::/8, is present as the least
+// specific entry for each version. Classify() uses these to recognize
+// multicast addresses. The Source field is false throughout since a
+// multicast address is never a valid source. The data-set is available from:
+//
+// - https://www.iana.org/assignments/multicast-addresses/multicast-addresses.xhtml
+var MulticastRegistry = NewRegistry([]*Reservation{
+	{getFromCIDR("224.0.0.0/4"), "Multicast", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.0.0/24"), "Local Network Control Block", []string{"RFC5771"}, false, false, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.1.0/24"), "Internetwork Control Block", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.2.0/23"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.4.0/22"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.8.0/21"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.16.0/20"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.32.0/19"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.64.0/18"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.0.128.0/17"), "AD-HOC Block I", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.2.0.0/16"), "SDP/SAP Block", []string{"RFC4566"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.3.0.0/16"), "AD-HOC Block II", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.4.0.0/16"), "AD-HOC Block II", []string{"RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("224.252.0.0/14"), "DIS Transient Groups", []string{}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("232.0.0.0/8"), "Source-Specific Multicast Block", []string{"RFC4607", "RFC4608"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.0.0.0/9"), "GLOP Block", []string{"RFC3180"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.128.0.0/10"), "GLOP Block", []string{"RFC3180"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.192.0.0/11"), "GLOP Block", []string{"RFC3180"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.224.0.0/12"), "GLOP Block", []string{"RFC3180"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.240.0.0/13"), "GLOP Block", []string{"RFC3180"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.248.0.0/14"), "GLOP Block", []string{"RFC3180"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.252.0.0/14"), "AD-HOC Block III", []string{"RFC3138", "RFC5771"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("233.252.0.0/24"), "MCAST-TEST-NET", []string{"RFC5771", "RFC6676"}, false, false, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("234.0.0.0/8"), "Unicast-Prefix-based IPv4 Multicast Addresses", []string{"RFC6034"}, true, true, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("239.0.0.0/8"), "Administratively Scoped Block", []string{"RFC2365"}, true, false, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("239.192.0.0/14"), "IPv4 Organization Local Scope", []string{"RFC2365"}, true, false, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("239.255.0.0/16"), "IPv4 Local Scope", []string{"RFC2365"}, true, false, false, false, true, time.Time{}, time.Time{}},
+	{getFromCIDR("ff00::/8"), "Multicast", []string{"RFC4291"}, true, true, false, false, true, time.Time{}, time.Time{}},
+})
+
+var solicitedNodePrefix = net.IP{0xff, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0xff, 0, 0, 0}
+
+// MulticastAddr holds the fields of an IPv6 multicast address
+type MulticastAddr struct {
+	// Flags is the 4bit flag field, see MulticastFlagTransient,
+	// MulticastFlagPrefix and MulticastFlagRP
+	Flags uint8
+
+	// Scope is the 4bit scope field
+	Scope MulticastScope
+
+	// Prefix is the unicast prefix the address was built from if
+	// MulticastFlagPrefix is set, RFC3306
+	Prefix iplib.Net
+
+	// RP is the rendezvous point embedded in the address if MulticastFlagRP
+	// is set, RFC3956
+	RP net.IP
+
+	// GroupID is the final 32bits of the address if MulticastFlagPrefix is
+	// set, or the final 112bits otherwise
+	GroupID []byte
+}
+
+// DecodeMulticastAddr returns the fields of the supplied IPv6 multicast
+// address, including the unicast prefix of an RFC3306 address and the
+// rendezvous point of an RFC3956 address
+func DecodeMulticastAddr(ip net.IP) (MulticastAddr, error) {
+	if len(ip) != 16 || iplib.Version(ip) != 6 {
+		return MulticastAddr{}, iplib.ErrNotIP6
+	}
+	if ip[0] != 0xff {
+		return MulticastAddr{}, ErrNotMulticast
+	}
+
+	m := MulticastAddr{
+		Flags: ip[1] >> 4,
+		Scope: MulticastScope(ip[1] & 0x0f),
+	}
+	if m.Flags&MulticastFlagPrefix == 0 {
+		m.GroupID = copyBytes(ip[2:])
+		return m, nil
+	}
+
+	plen := int(ip[3])
+	if plen > 64 {
+		return MulticastAddr{}, iplib.ErrBadMaskLength
+	}
+	m.GroupID = copyBytes(ip[12:])
+
+	xip := make(net.IP, 16)
+	copy(xip, ip[4:12])
+	m.Prefix = iplib.NewNet(xip, plen)
+
+	if m.Flags&MulticastFlagRP != 0 {
+		m.RP = make(net.IP, 16)
+		copy(m.RP, m.Prefix.IP)
+		m.RP[15] = ip[2] & 0x0f
+	}
+	return m, nil
+}
+
+// MakeSolicitedNodeAddr returns the RFC4291 solicited-node multicast address
+// for the supplied v6 address, ff02::1:ffXX:XXXX where the final 24bits are
+// taken from the address. v4 addresses, including those in IPv4-mapped form,
+// return iplib.ErrNotIP6
+func MakeSolicitedNodeAddr(ip net.IP) (net.IP, error) {
+	if len(ip) != 16 || ip.To4() != nil {
+		return nil, iplib.ErrNotIP6
+	}
+
+	xip := make(net.IP, 16)
+	copy(xip, solicitedNodePrefix)
+	copy(xip[13:], ip[13:])
+	return xip, nil
+}
+
+// MakeUnicastPrefixMulticastAddr returns the RFC3306 multicast address for
+// the supplied group within a unicast prefix, which must be no longer than
+// /64
+func MakeUnicastPrefixMulticastAddr(prefix iplib.Net, scope MulticastScope, groupID uint32) (net.IP, error) {
+	if prefix.Version() != 6 {
+		return nil, iplib.ErrNotIP6
+	}
+	plen, _ := prefix.Mask.Size()
+	if plen > 64 {
+		return nil, iplib.ErrBadMaskLength
+	}
+
+	xip := make(net.IP, 16)
+	xip[0] = 0xff
+	xip[1] = (MulticastFlagPrefix|MulticastFlagTransient)<<4 | byte(scope&0x0f)
+	xip[3] = byte(plen)
+	copy(xip[4:12], prefix.IP.Mask(prefix.Mask))
+	putGroupID(xip, groupID)
+	return xip, nil
+}
+
+// MakeEmbeddedRPAddr returns the RFC3956 multicast address for the supplied
+// group with rp, the rendezvous point, embedded in it. The RP must be made up
+// of a prefix of plen bits, which must be between 1 and 64, followed by
+// zeros and a 4bit RP interface ID; if not ErrBadRP is returned
+func MakeEmbeddedRPAddr(rp net.IP, plen int, scope MulticastScope, groupID uint32) (net.IP, error) {
+	if len(rp) != 16 || iplib.Version(rp) != 6 {
+		return nil, iplib.ErrNotIP6
+	}
+	if plen < 1 || plen > 64 {
+		return nil, iplib.ErrBadMaskLength
+	}
+
+	prefix := iplib.NewNet(rp, plen)
+	for i, b := range rp {
+		if i == 15 {
+			b &^= 0x0f
+		}
+		if b != prefix.IP[i] {
+			return nil, ErrBadRP
+		}
+	}
+
+	xip := make(net.IP, 16)
+	xip[0] = 0xff
+	xip[1] = (MulticastFlagRP|MulticastFlagPrefix|MulticastFlagTransient)<<4 | byte(scope&0x0f)
+	xip[2] = rp[15] & 0x0f
+	xip[3] = byte(plen)
+	copy(xip[4:12], prefix.IP)
+	putGroupID(xip, groupID)
+	return xip, nil
+}
+
+func copyBytes(b []byte) []byte {
+	xb := make([]byte, len(b))
+	copy(xb, b)
+	return xb
+}
+
+func putGroupID(ip net.IP, groupID uint32) {
+	ip[12] = byte(groupID >> 24)
+	ip[13] = byte(groupID >> 16)
+	ip[14] = byte(groupID >> 8)
+	ip[15] = byte(groupID)
+}
@@ -0,0 +1,171 @@
+package iana
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var MulticastRegistryTests = []struct {
+	name    string
+	address string
+	title   string
+	global  bool
+}{
+	{"LocalNetworkControl", "224.0.0.251", "Local Network Control Block", false},
+	{"AdHocI", "224.0.99.1", "AD-HOC Block I", true},
+	{"SSM", "232.1.2.3", "Source-Specific Multicast Block", true},
+	{"GLOP", "233.251.255.1", "GLOP Block", true},
+	{"TestNet", "233.252.0.5", "MCAST-TEST-NET", false},
+	{"AdHocIII", "233.253.0.5", "AD-HOC Block III", true},
+	{"AdminScoped", "239.1.1.1", "Administratively Scoped Block", false},
+	{"LocalScope", "239.255.255.250", "IPv4 Local Scope", false},
+}
+
+func TestMulticastRegistry(t *testing.T) {
+	for _, tt := range MulticastRegistryTests {
+		res := MulticastRegistry.GetReservationsForIP(net.ParseIP(tt.address))
+		if len(res) == 0 {
+			t.Errorf("'%s' expected a reservation for %s", tt.name, tt.address)
+			continue
+		}
+		if res[0].Title != tt.title {
+			t.Errorf("'%s' expected '%s', got '%s'", tt.name, tt.title, res[0].Title)
+		}
+		n := iplib.NewNet(net.ParseIP(tt.address).To4(), 32)
+		if g := MulticastRegistry.IsGlobal(n); g != tt.global {
+			t.Errorf("'%s' expected IsGlobal %t, got %t", tt.name, tt.global, g)
+		}
+	}
+
+	if res := MulticastRegistry.GetReservationsForIP(net.ParseIP("226.1.1.1")); len(res) != 1 || res[0].Title != "Multicast" {
+		t.Errorf("expected only the enclosing multicast block for reserved 226.1.1.1, got %d reservations", len(res))
+	}
+}
+
+var DecodeMulticastTests = []struct {
+	name    string
+	address string
+	flags   uint8
+	scope   MulticastScope
+	prefix  string
+	rp      string
+	groupID string
+	err     error
+}{
+	{"AllNodes", "ff02::1", 0, ScopeLinkLocal, "", "", "0000000000000000000000000001", nil},
+	{"Transient", "ff15::1234", MulticastFlagTransient, ScopeSiteLocal, "", "", "0000000000000000000000001234", nil},
+	{"RFC3306", "ff3e:30:2001:db8:1::1234", MulticastFlagTransient | MulticastFlagPrefix, ScopeGlobal, "2001:db8:1::/48", "", "00001234", nil},
+	{"RFC3956", "ff7e:740:2001:db8:beef:feed::1234", MulticastFlagTransient | MulticastFlagPrefix | MulticastFlagRP, ScopeGlobal, "2001:db8:beef:feed::/64", "2001:db8:beef:feed::7", "00001234", nil},
+	{"BadPlen", "ff3e:41:2001:db8:1::1234", 0, 0, "", "", "", iplib.ErrBadMaskLength},
+	{"NotMulticast", "2001:db8::1", 0, 0, "", "", "", ErrNotMulticast},
+	{"NotIP6", "224.0.0.1", 0, 0, "", "", "", iplib.ErrNotIP6},
+}
+
+func TestDecodeMulticastAddr(t *testing.T) {
+	for _, tt := range DecodeMulticastTests {
+		ip := net.ParseIP(tt.address)
+		if tt.err == iplib.ErrNotIP6 {
+			ip = ip.To4()
+		}
+		m, err := DecodeMulticastAddr(ip)
+		if err != tt.err {
+			t.Errorf("'%s' expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if m.Flags != tt.flags || m.Scope != tt.scope {
+			t.Errorf("'%s' expected flags %x scope %s, got %x %s", tt.name, tt.flags, tt.scope, m.Flags, m.Scope)
+		}
+		if tt.prefix != "" && m.Prefix.String() != tt.prefix {
+			t.Errorf("'%s' expected prefix %s, got %s", tt.name, tt.prefix, m.Prefix.String())
+		}
+		if tt.rp != "" && !m.RP.Equal(net.ParseIP(tt.rp)) {
+			t.Errorf("'%s' expected RP %s, got %s", tt.name, tt.rp, m.RP)
+		}
+		if tt.rp == "" && m.RP != nil {
+			t.Errorf("'%s' expected no RP, got %s", tt.name, m.RP)
+		}
+		if g := hex.EncodeToString(m.GroupID); g != tt.groupID {
+			t.Errorf("'%s' expected group ID %s, got %s", tt.name, tt.groupID, g)
+		}
+	}
+}
+
+var SolicitedNodeTests = []struct {
+	name string
+	ip   net.IP
+	out  string
+	err  error
+}{
+	{"LinkLocal", net.ParseIP("fe80::2aa:ff:fe28:9c5a"), "ff02::1:ff28:9c5a", nil},
+	{"Global", net.ParseIP("2001:db8::1:2:3"), "ff02::1:ff02:3", nil},
+	{"IP4", net.ParseIP("10.1.1.1").To4(), "", iplib.ErrNotIP6},
+	{"IP4Mapped", net.ParseIP("::ffff:10.1.1.1"), "", iplib.ErrNotIP6},
+}
+
+func TestMakeSolicitedNodeAddr(t *testing.T) {
+	for _, tt := range SolicitedNodeTests {
+		ip, err := MakeSolicitedNodeAddr(tt.ip)
+		if err != tt.err {
+			t.Errorf("'%s' expected error '%v', got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err == nil && !ip.Equal(net.ParseIP(tt.out)) {
+			t.Errorf("'%s' expected %s, got %s", tt.name, tt.out, ip)
+		}
+	}
+}
+
+func TestMakeUnicastPrefixMulticastAddr(t *testing.T) {
+	_, n, _ := iplib.ParseCIDR("2001:db8:1::/48")
+	ip, err := MakeUnicastPrefixMulticastAddr(n, ScopeGlobal, 0x1234)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !ip.Equal(net.ParseIP("ff3e:30:2001:db8:1::1234")) {
+		t.Errorf("expected ff3e:30:2001:db8:1::1234, got %s", ip)
+	}
+
+	_, n, _ = iplib.ParseCIDR("2001:db8:1::/80")
+	if _, err := MakeUnicastPrefixMulticastAddr(n, ScopeGlobal, 0x1234); err != iplib.ErrBadMaskLength {
+		t.Errorf("expected ErrBadMaskLength for /80, got %v", err)
+	}
+}
+
+var EmbeddedRPTests = []struct {
+	name  string
+	rp    string
+	plen  int
+	group string
+	err   error
+}{
+	{"RFC3956", "2001:db8:beef:feed::7", 64, "ff7e:740:2001:db8:beef:feed::1234", nil},
+	{"ShortPrefix", "2001:db8::f", 32, "ff7e:f20:2001:db8::1234", nil},
+	{"BadRP", "2001:db8:beef:feed::17", 64, "", ErrBadRP},
+	{"BadPlen", "2001:db8:beef:feed::7", 65, "", iplib.ErrBadMaskLength},
+}
+
+func TestMakeEmbeddedRPAddr(t *testing.T) {
+	for _, tt := range EmbeddedRPTests {
+		ip, err := MakeEmbeddedRPAddr(net.ParseIP(tt.rp), tt.plen, ScopeGlobal, 0x1234)
+		if err != tt.err {
+			t.Errorf("'%s' expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !ip.Equal(net.ParseIP(tt.group)) {
+			t.Errorf("'%s' expected %s, got %s", tt.name, tt.group, ip)
+		}
+		m, _ := DecodeMulticastAddr(ip)
+		if !m.RP.Equal(net.ParseIP(tt.rp)) {
+			t.Errorf("'%s' expected to decode RP %s, got %s", tt.name, tt.rp, m.RP)
+		}
+	}
+}
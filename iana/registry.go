@@ -0,0 +1,228 @@
+package iana
+
+import (
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/kenits/iplib"
+)
+
+// ReservationRegistry is a queryable set of reservations from IANA's
+// special-purpose address registries. Each ReservationRegistry is independent
+// of every other, so an application can hold the embedded DefaultRegistry
+// alongside one loaded from a newer copy of IANA's data, or a test can build
+// one containing only the entries it cares about. A ReservationRegistry is
+// safe for concurrent use.
+type ReservationRegistry struct {
+	mu           sync.RWMutex
+	reservations []*Reservation
+	index        *index
+}
+
+// NewRegistry returns a new ReservationRegistry containing the supplied
+// reservations. It is typically given the output of LoadFile(), LoadCSV() or
+// LoadXML(), or a list built by hand
+func NewRegistry(reservations []*Reservation) *ReservationRegistry {
+	r := &ReservationRegistry{}
+	r.Merge(reservations...)
+	return r
+}
+
+// Clone returns a new ReservationRegistry containing copies of the
+// reservations in this one, so changes made to the clone, whether with
+// Merge() and Remove() or to the reservations it returns, do not affect the
+// original
+func (r *ReservationRegistry) Clone() *ReservationRegistry {
+	reservations := r.Reservations()
+	for i, res := range reservations {
+		reservations[i] = res.copy()
+	}
+	return NewRegistry(reservations)
+}
+
+// Merge adds the supplied reservations to the registry. A reservation for a
+// network already present in the registry replaces the existing entry, so
+// merging a freshly loaded data-set into a clone of DefaultRegistry will
+// update any reservation IANA has changed while keeping the rest
+func (r *ReservationRegistry) Merge(reservations ...*Reservation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+LOOP:
+	for _, res := range reservations {
+		for i, xres := range r.reservations {
+			if sameNetwork(xres.Network, res.Network) {
+				r.reservations[i] = res
+				continue LOOP
+			}
+		}
+		r.reservations = append(r.reservations, res)
+	}
+	r.index = newIndex(r.reservations)
+}
+
+// Remove deletes any reservation for exactly the supplied network from the
+// registry, returning true if one was found
+func (r *ReservationRegistry) Remove(n iplib.Net) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, res := range r.reservations {
+		if sameNetwork(res.Network, n) {
+			r.reservations = append(r.reservations[:i], r.reservations[i+1:]...)
+			r.index = newIndex(r.reservations)
+			return true
+		}
+	}
+	return false
+}
+
+// Reservations returns a list of every reservation in the registry
+func (r *ReservationRegistry) Reservations() []*Reservation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservations := make([]*Reservation, len(r.reservations))
+	copy(reservations, r.reservations)
+	return reservations
+}
+
+// GetReservationsForNetwork returns a list of any reserved networks in the
+// registry that are either part of the supplied network or that the supplied
+// network is part of, ordered from most to least specific
+func (r *ReservationRegistry) GetReservationsForNetwork(n iplib.Net) []*Reservation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.index.lookupNet(n)
+}
+
+// GetReservationsForIP returns a list of any reserved networks in the
+// registry that the supplied IP is part of, ordered from most to least
+// specific
+func (r *ReservationRegistry) GetReservationsForIP(ip net.IP) []*Reservation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.index.lookupIP(ip)
+}
+
+// GetRFCsForNetwork returns a list of all RFCs that apply to the given
+// network
+func (r *ReservationRegistry) GetRFCsForNetwork(n iplib.Net) []string {
+	rfclist := []string{}
+	reservations := r.GetReservationsForNetwork(n)
+	if len(reservations) > 0 {
+		for _, res := range reservations {
+		LOOP:
+			for _, rfc := range res.RFC {
+				for _, xrfc := range rfclist {
+					if xrfc == rfc {
+						continue LOOP
+					}
+				}
+				rfclist = append(rfclist, rfc)
+			}
+		}
+		sort.Strings(rfclist)
+	}
+	return rfclist
+}
+
+// IsForwardable will return false if the given iplib.Net contains or is
+// contained in a network that is marked not-forwardable in the registry.
+// IANA defines a forwardable network as one where "...a router may forward an
+// IP datagram whose destination address is drawn from the allocated special-
+// purpose address block between external interfaces." The default is 'true'
+func (r *ReservationRegistry) IsForwardable(n iplib.Net) bool {
+	reservations := r.GetReservationsForNetwork(n)
+	for _, res := range reservations {
+		if res.Forwardable == false {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGlobal will return false if the given iplib.Net contains or is contained
+// in a network that is marked not-global in the registry. IANA defines a
+// global network as one where "...an IP datagram whose destination address is
+// drawn from the allocated special-purpose address block is forwardable
+// beyond a specified administrative domain." The default is 'true'
+func (r *ReservationRegistry) IsGlobal(n iplib.Net) bool {
+	reservations := r.GetReservationsForNetwork(n)
+	for _, res := range reservations {
+		if res.Global == false {
+			return false
+		}
+	}
+	return true
+}
+
+// IsReserved  will return true if the given iplib.Net contains or is
+// contained in a network that is marked reserved-by-protocol in the
+// registry. IANA defines a reserved network as one where "...the RFC that
+// created the special-purpose address block requires all compliant IP
+// implementations to behave in a special way when processing packets either
+// to or from addresses contained by the address block." The default is 'false'
+func (r *ReservationRegistry) IsReserved(n iplib.Net) bool {
+	reservations := r.GetReservationsForNetwork(n)
+	for _, res := range reservations {
+		if res.Reserved == true {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidDestination will return false if the given iplib.Net contains or is
+// contained in a network that the registry says may not be used as the
+// destination address of an IP datagram. Where reservations are nested the
+// most specific one containing the network decides, since IANA marks the
+// enclosing blocks as not valid "unless allowed by a more specific
+// allocation". The default is 'true'
+func (r *ReservationRegistry) IsValidDestination(n iplib.Net) bool {
+	return r.checkMostSpecific(n, func(res *Reservation) bool {
+		return res.Destination
+	})
+}
+
+// IsValidSource will return false if the given iplib.Net contains or is
+// contained in a network that the registry says may not be used as the
+// source address of an IP datagram. As with IsValidDestination() the most
+// specific reservation containing the network decides. The default is 'true'
+func (r *ReservationRegistry) IsValidSource(n iplib.Net) bool {
+	return r.checkMostSpecific(n, func(res *Reservation) bool {
+		return res.Source
+	})
+}
+
+// checkMostSpecific evaluates f against the most specific reservation that
+// contains n, and against every reservation contained within n, returning
+// false if any of them do
+func (r *ReservationRegistry) checkMostSpecific(n iplib.Net, f func(res *Reservation) bool) bool {
+	var parent *Reservation
+	var parentLen = -1
+
+	for _, res := range r.GetReservationsForNetwork(n) {
+		if res.Network.ContainsNet(n) {
+			if ones, _ := res.Network.Mask.Size(); ones > parentLen {
+				parent, parentLen = res, ones
+			}
+			continue
+		}
+		if f(res) == false {
+			return false
+		}
+	}
+	if parent != nil {
+		return f(parent)
+	}
+	return true
+}
+
+// sameNetwork returns true if a and b describe exactly the same block
+func sameNetwork(a, b iplib.Net) bool {
+	return a.Version() == b.Version() && iplib.CompareNets(a, b) == 0
+}
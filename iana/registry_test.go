@@ -0,0 +1,236 @@
+package iana
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+func TestNewRegistry(t *testing.T) {
+	r := NewRegistry([]*Reservation{
+		{Network: getFromCIDR("10.0.0.0/8"), Title: "Private-Use", RFC: []string{"RFC1918"}, Forwardable: true},
+		{Network: getFromCIDR("198.18.0.0/15"), Title: "Benchmarking", RFC: []string{"RFC2544"}, Forwardable: true},
+	})
+
+	if l := len(r.Reservations()); l != 2 {
+		t.Errorf("expected 2 reservations, got %d", l)
+	}
+	if res := r.GetReservationsForIP(net.ParseIP("192.168.1.1")); len(res) != 0 {
+		t.Errorf("expected custom registry to ignore 192.168.1.1, got %d reservations", len(res))
+	}
+	if res := DefaultRegistry.GetReservationsForIP(net.ParseIP("192.168.1.1")); len(res) != 1 {
+		t.Errorf("expected default registry to have 1 reservation for 192.168.1.1, got %d", len(res))
+	}
+	if r.IsGlobal(getFromCIDR("10.1.0.0/16")) {
+		t.Error("expected 10.1.0.0/16 to be non-global in custom registry")
+	}
+}
+
+var MergeTests = []struct {
+	name    string
+	network string
+	title   string
+	count   int
+}{
+	{
+		"Override",
+		"10.0.0.0/8",
+		"Replaced",
+		2,
+	},
+	{
+		"Add",
+		"10.0.0.0/9",
+		"Added",
+		3,
+	},
+	{
+		"OverrideV6",
+		"2001:db8::/32",
+		"Replaced",
+		2,
+	},
+}
+
+func TestRegistry_Merge(t *testing.T) {
+	for _, tt := range MergeTests {
+		r := NewRegistry([]*Reservation{
+			{Network: getFromCIDR("10.0.0.0/8"), Title: "Private-Use"},
+			{Network: getFromCIDR("2001:db8::/32"), Title: "Documentation"},
+		})
+		r.Merge(&Reservation{Network: getFromCIDR(tt.network), Title: tt.title})
+
+		if l := len(r.Reservations()); l != tt.count {
+			t.Errorf("'%s' expected %d reservations, got %d", tt.name, tt.count, l)
+		}
+
+		found := false
+		for _, res := range r.Reservations() {
+			if res.Network.String() == tt.network && res.Title == tt.title {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("'%s' expected to find '%s' for %s", tt.name, tt.title, tt.network)
+		}
+	}
+}
+
+func TestRegistry_Clone(t *testing.T) {
+	r := DefaultRegistry.Clone()
+	_, n, _ := iplib.ParseCIDR("10.0.0.0/8")
+
+	res := r.GetReservationsForNetwork(n)[0]
+	res.Global = true
+	res.RFC[0] = "RFC0"
+	res.Network.IP[0] = 11
+
+	if r.IsGlobal(n) == DefaultRegistry.IsGlobal(n) {
+		t.Error("expected a change to a cloned reservation to affect the clone")
+	}
+	orig := DefaultRegistry.GetReservationsForNetwork(n)
+	if len(orig) != 1 || orig[0].Global || orig[0].RFC[0] != "RFC1918" || orig[0].Network.String() != "10.0.0.0/8" {
+		t.Error("expected a change to a cloned reservation to leave DefaultRegistry alone")
+	}
+}
+
+func TestRegistry_Deprecated(t *testing.T) {
+	if len(Registry) != len(DefaultRegistry.Reservations()) {
+		t.Errorf("expected Registry to hold the %d default reservations, got %d", len(DefaultRegistry.Reservations()), len(Registry))
+	}
+	for i, res := range DefaultRegistry.Reservations() {
+		if Registry[i] != res {
+			t.Errorf("expected Registry[%d] to be %s, got %s", i, res.Network.String(), Registry[i].Network.String())
+		}
+	}
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	r := DefaultRegistry.Clone()
+	_, n, _ := iplib.ParseCIDR("192.168.0.0/16")
+
+	if !r.Remove(n) {
+		t.Fatal("expected Remove to find 192.168.0.0/16")
+	}
+	if r.Remove(n) {
+		t.Error("expected second Remove of 192.168.0.0/16 to find nothing")
+	}
+	if res := r.GetReservationsForNetwork(n); len(res) != 0 {
+		t.Errorf("expected no reservations after Remove, got %d", len(res))
+	}
+	if res := DefaultRegistry.GetReservationsForNetwork(n); len(res) != 1 {
+		t.Errorf("expected Remove on a clone to leave DefaultRegistry alone, got %d reservations", len(res))
+	}
+	if l1, l2 := len(r.Reservations()), len(DefaultRegistry.Reservations()); l1 != l2-1 {
+		t.Errorf("expected clone to have %d reservations, got %d", l2-1, l1)
+	}
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := DefaultRegistry.Clone()
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			r.Merge(&Reservation{Network: getFromCIDR("203.0.113.0/24"), Title: "Documentation (TEST-NET-3)"})
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		r.GetReservationsForIP(net.ParseIP("203.0.113.1"))
+	}
+	<-done
+}
+
+var IndexTests = []string{
+	"0.0.0.0/0",
+	"0.0.0.0/8",
+	"0.0.0.0/32",
+	"10.20.30.0/24",
+	"192.0.0.0/12",
+	"192.0.0.170/32",
+	"198.18.0.0/15",
+	"255.255.255.255/32",
+	"::1/128",
+	"::ffff:0:0/96",
+	"2001::/16",
+	"2001::/23",
+	"2001:db8::/48",
+	"fe80::1/128",
+}
+
+func TestRegistry_IndexMatchesScan(t *testing.T) {
+	all := DefaultRegistry.Reservations()
+	for _, s := range IndexTests {
+		_, n, _ := iplib.ParseCIDR(s)
+
+		got := DefaultRegistry.GetReservationsForNetwork(n)
+		want := linearReservationsForNetwork(all, n)
+		if !sameReservations(got, want) {
+			t.Errorf("%s: index returned %d reservations, scan returned %d", s, len(got), len(want))
+		}
+		checkOrder(t, s, got)
+
+		got = DefaultRegistry.GetReservationsForIP(n.IP)
+		want = linearReservationsForIP(all, n.IP)
+		if !sameReservations(got, want) {
+			t.Errorf("%s: index returned %d reservations for IP, scan returned %d", s, len(got), len(want))
+		}
+		checkOrder(t, s, got)
+	}
+}
+
+func TestRegistry_MappedBlock(t *testing.T) {
+	_, n, _ := iplib.ParseCIDR("::/0")
+	found := false
+	for _, res := range DefaultRegistry.GetReservationsForNetwork(n) {
+		if res.Title == "IPv4-mapped Address" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected ::/0 to contain the IPv4-mapped block")
+	}
+
+	for _, res := range DefaultRegistry.GetReservationsForIP(net.ParseIP("1.2.3.4")) {
+		if res.Title == "IPv4-mapped Address" {
+			t.Error("expected v4 address to not match the IPv4-mapped block")
+		}
+	}
+}
+
+func TestRegistry_ZeroValue(t *testing.T) {
+	r := &ReservationRegistry{}
+	if res := r.GetReservationsForIP(net.ParseIP("10.0.0.1")); len(res) != 0 {
+		t.Errorf("expected empty registry to return no reservations, got %d", len(res))
+	}
+	if !r.IsGlobal(getFromCIDR("10.0.0.0/8")) {
+		t.Error("expected empty registry to report 10.0.0.0/8 as global")
+	}
+}
+
+func checkOrder(t *testing.T, name string, reservations []*Reservation) {
+	for i := 1; i < len(reservations); i++ {
+		a, _ := reservations[i-1].Network.Mask.Size()
+		b, _ := reservations[i].Network.Mask.Size()
+		if a < b {
+			t.Errorf("%s: %s listed before more specific %s", name, reservations[i-1].Network.String(), reservations[i].Network.String())
+		}
+	}
+}
+
+func sameReservations(a, b []*Reservation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[*Reservation]bool)
+	for _, res := range a {
+		seen[res] = true
+	}
+	for _, res := range b {
+		if !seen[res] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package iana
+
+import (
+	"net"
+	"sort"
+
+	"github.com/kenits/iplib"
+)
+
+// index is a pair of binary prefix trees, one per IP version, holding the
+// reservations in a ReservationRegistry. Each reservation hangs off the node
+// found by walking the bits of its network address to the depth of its mask,
+// so a lookup touches at most one node per bit of the address
+type index struct {
+	v4 *trieNode
+	v6 *trieNode
+}
+
+type trieNode struct {
+	child [2]*trieNode
+	res   *Reservation
+}
+
+// newIndex returns an index built from the supplied reservations. If more
+// than one reservation is given for the same network the last one wins
+func newIndex(reservations []*Reservation) *index {
+	idx := &index{v4: &trieNode{}, v6: &trieNode{}}
+	for _, res := range reservations {
+		key, root := idx.keyAndRoot(res.Network.IP, res.Network.Version())
+		ones, _ := res.Network.Mask.Size()
+
+		node := root
+		for i := 0; i < ones; i++ {
+			b := bitAt(key, i)
+			if node.child[b] == nil {
+				node.child[b] = &trieNode{}
+			}
+			node = node.child[b]
+		}
+		node.res = res
+	}
+	return idx
+}
+
+// lookupIP returns every reservation containing ip, ordered from most to
+// least specific. v4 addresses, including those in v4-mapped form, are only
+// compared against v4 reservations
+func (idx *index) lookupIP(ip net.IP) []*Reservation {
+	reservations := []*Reservation{}
+	if idx == nil {
+		return reservations
+	}
+
+	key, node := idx.keyAndRoot(ip, iplib.EffectiveVersion(ip))
+	if key == nil {
+		return reservations
+	}
+	for i := 0; node != nil; i++ {
+		if node.res != nil {
+			reservations = append(reservations, node.res)
+		}
+		if i == len(key)*8 {
+			break
+		}
+		node = node.child[bitAt(key, i)]
+	}
+	reverse(reservations)
+	return reservations
+}
+
+// lookupNet returns every reservation that contains or is contained in n,
+// ordered from most to least specific. Those containing n are collected on
+// the way down to the node for n, those contained in it by walking the
+// subtree below that node a level at a time
+func (idx *index) lookupNet(n iplib.Net) []*Reservation {
+	reservations := []*Reservation{}
+	if idx == nil {
+		return reservations
+	}
+
+	key, node := idx.keyAndRoot(n.IP, n.Version())
+	if key == nil {
+		return reservations
+	}
+	ones, _ := n.Mask.Size()
+
+	parents := []*Reservation{}
+	for i := 0; node != nil; i++ {
+		if node.res != nil {
+			parents = append(parents, node.res)
+		}
+		if i == ones {
+			break
+		}
+		node = node.child[bitAt(key, i)]
+	}
+
+	if node != nil {
+		reservations = node.collect()
+	}
+
+	reverse(parents)
+	return append(reservations, parents...)
+}
+
+// keyAndRoot returns ip in the byte form used to walk the tree for the given
+// version, along with the root of that tree
+func (idx *index) keyAndRoot(ip net.IP, version int) ([]byte, *trieNode) {
+	if version == 4 {
+		return ip.To4(), idx.v4
+	}
+	return ip.To16(), idx.v6
+}
+
+// collect returns the reservations held in the subtree under node, ordered
+// from most to least specific
+func (node *trieNode) collect() []*Reservation {
+	found := []depthReservation{}
+	for _, c := range node.child {
+		found = c.walk(1, found)
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].depth > found[j].depth
+	})
+
+	reservations := make([]*Reservation, len(found))
+	for i, f := range found {
+		reservations[i] = f.res
+	}
+	return reservations
+}
+
+// depthReservation is a reservation found by walk() and the depth of the
+// node holding it, which is its mask length relative to the walk's start
+type depthReservation struct {
+	depth int
+	res   *Reservation
+}
+
+// walk appends the reservations held by node and every node under it to
+// found
+func (node *trieNode) walk(depth int, found []depthReservation) []depthReservation {
+	if node == nil {
+		return found
+	}
+	if node.res != nil {
+		found = append(found, depthReservation{depth, node.res})
+	}
+	for _, c := range node.child {
+		found = c.walk(depth+1, found)
+	}
+	return found
+}
+
+func bitAt(key []byte, i int) int {
+	return int(key[i/8]>>(7-uint(i%8))) & 1
+}
+
+func reverse(reservations []*Reservation) {
+	for i, j := 0, len(reservations)-1; i < j; i, j = i+1, j-1 {
+		reservations[i], reservations[j] = reservations[j], reservations[i]
+	}
+}
@@ -0,0 +1,213 @@
+package iid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"strconv"
+
+	"github.com/kenits/iplib"
+)
+
+// Kind describes how an IID appears to have been generated, as determined
+// by AnalyzeIID()
+type Kind int
+
+const (
+	// KindRandomized is an IID with no recognizable structure, such as one
+	// from GenerateRFC7217Addr() or GenerateTempIID()
+	KindRandomized Kind = iota
+
+	// KindEUI64 is an IID built from a 48bit hardware address, see
+	// MakeEUI64Addr()
+	KindEUI64
+
+	// KindLowByte is an IID that is zero except for its final bits, such as
+	// ::1 or ::2, typically assigned by hand or by DHCPv6
+	KindLowByte
+
+	// KindEmbeddedIPv4 is an IID that holds a v4 address, either in its
+	// final 32bits (::c000:201) or with an octet in each 16bit word written
+	// in decimal (::192:0:2:1)
+	KindEmbeddedIPv4
+
+	// KindEmbeddedPort is an IID holding the well-known port of the service
+	// running on the host, either as a number (::1bb) or written in decimal
+	// (::443)
+	KindEmbeddedPort
+
+	// KindWordy is an IID spelling out words in hex, such as ::dead:beef
+	KindWordy
+
+	// KindISATAP is an RFC5214 ISATAP IID, 0000:5efe or 0200:5efe followed
+	// by a v4 address
+	KindISATAP
+
+	// KindReserved is an IID in the IANA reserved list, see Registry
+	KindReserved
+)
+
+var kindNames = map[Kind]string{
+	KindRandomized:   "randomized",
+	KindEUI64:        "eui64",
+	KindLowByte:      "low-byte",
+	KindEmbeddedIPv4: "embedded-ipv4",
+	KindEmbeddedPort: "embedded-port",
+	KindWordy:        "wordy",
+	KindISATAP:       "isatap",
+	KindReserved:     "reserved",
+}
+
+// wellKnownPorts are the service ports looked for by AnalyzeIID()
+var wellKnownPorts = map[int]bool{
+	21: true, 22: true, 23: true, 25: true, 53: true, 80: true, 110: true,
+	123: true, 143: true, 389: true, 443: true, 465: true, 587: true,
+	636: true, 853: true, 993: true, 995: true, 1194: true, 1723: true,
+	3306: true, 3389: true, 5060: true, 5432: true, 8080: true, 8443: true,
+}
+
+// hexWords are the 16bit hex-speak words looked for by AnalyzeIID()
+var hexWords = map[string]bool{
+	"1337": true, "abba": true, "babe": true, "bead": true, "beef": true,
+	"c0de": true, "c0c0": true, "cafe": true, "d00d": true, "dada": true,
+	"dead": true, "deaf": true, "deed": true, "f00d": true, "face": true,
+	"fade": true, "feed": true,
+}
+
+// Analysis is the result of AnalyzeIID()
+type Analysis struct {
+	// Kind is the most likely way the IID was generated
+	Kind Kind
+
+	// Confidence is between 0 and 1 and reflects how unlikely it is that an
+	// IID of some other Kind would look like this one by chance
+	Confidence float64
+
+	// HardwareAddr is the hardware address of a KindEUI64 IID, assuming
+	// ScopeInvert as RFC4291 specifies
+	HardwareAddr net.HardwareAddr
+
+	// IP4 is the address embedded in a KindEmbeddedIPv4 or KindISATAP IID
+	IP4 net.IP
+
+	// Port is the port embedded in a KindEmbeddedPort IID
+	Port int
+
+	// Words are the hex-speak words found in a KindWordy IID
+	Words []string
+
+	// Reservations are the entries from Registry matching a KindReserved IID
+	Reservations []*Reservation
+}
+
+// String returns the name of the Kind
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// AnalyzeIID makes an educated guess at how the IID of the supplied v6
+// address was generated, in the manner of the addr6 tool from the SI6
+// Networks IPv6 Toolkit. The checks are made in order of specificity:
+// reserved, ISATAP, EUI-64, embedded port, low-byte, embedded IPv4 and
+// wordy; an IID matching none of them is considered randomized, with a
+// confidence based on how close to half of its bits are set
+func AnalyzeIID(ip net.IP) (Analysis, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return Analysis{}, iplib.ErrNotIP6
+	}
+	b := ip[8:]
+	w := []uint16{
+		binary.BigEndian.Uint16(b[0:2]),
+		binary.BigEndian.Uint16(b[2:4]),
+		binary.BigEndian.Uint16(b[4:6]),
+		binary.BigEndian.Uint16(b[6:8]),
+	}
+
+	if r := GetReservationsForIP(ip); len(r) > 0 {
+		return Analysis{Kind: KindReserved, Confidence: 1, Reservations: r}, nil
+	}
+
+	if w[0]&0xfcff == 0 && w[1] == 0x5efe {
+		return Analysis{Kind: KindISATAP, Confidence: 1, IP4: net.IPv4(b[4], b[5], b[6], b[7])}, nil
+	}
+
+	if hw, err := GetEUI64HardwareAddr(ip, ScopeInvert); err == nil {
+		return Analysis{Kind: KindEUI64, Confidence: 0.9, HardwareAddr: hw}, nil
+	}
+
+	if w[0] == 0 && w[1] == 0 && w[2] == 0 {
+		if p, ok := hexDecimal(w[3]); ok && wellKnownPorts[p] {
+			return Analysis{Kind: KindEmbeddedPort, Confidence: 0.8, Port: p}, nil
+		}
+		if w[3] > 0xff && wellKnownPorts[int(w[3])] {
+			return Analysis{Kind: KindEmbeddedPort, Confidence: 0.6, Port: int(w[3])}, nil
+		}
+		if w[3] <= 0xff {
+			return Analysis{Kind: KindLowByte, Confidence: 1}, nil
+		}
+		return Analysis{Kind: KindLowByte, Confidence: 0.9}, nil
+	}
+
+	if ip4, ok := decimalIP4(w); ok {
+		return Analysis{Kind: KindEmbeddedIPv4, Confidence: 0.9, IP4: ip4}, nil
+	}
+
+	if w[0] == 0 && w[1] == 0 {
+		if b[4] != 0 {
+			return Analysis{Kind: KindEmbeddedIPv4, Confidence: 0.8, IP4: net.IPv4(b[4], b[5], b[6], b[7])}, nil
+		}
+		return Analysis{Kind: KindLowByte, Confidence: 0.6}, nil
+	}
+
+	words := []string{}
+	for _, x := range w {
+		if s := fmt.Sprintf("%04x", x); hexWords[s] {
+			words = append(words, s)
+		}
+	}
+	if len(words) > 0 {
+		c := 0.6
+		if len(words) > 1 {
+			c = 0.9
+		}
+		return Analysis{Kind: KindWordy, Confidence: c, Words: words}, nil
+	}
+
+	ones := bits.OnesCount64(binary.BigEndian.Uint64(b))
+	d := ones - 32
+	if d < 0 {
+		d = -d
+	}
+	return Analysis{Kind: KindRandomized, Confidence: 1 - float64(d)/32}, nil
+}
+
+// decimalIP4 reads a v4 address written with one octet per 16bit word in
+// decimal, as in ::192:0:2:1. The first octet must be non-zero
+func decimalIP4(w []uint16) (net.IP, bool) {
+	ip4 := make(net.IP, 4)
+	for i, x := range w {
+		n, ok := hexDecimal(x)
+		if !ok || n > 255 {
+			return nil, false
+		}
+		ip4[i] = byte(n)
+	}
+	if ip4[0] == 0 {
+		return nil, false
+	}
+	return net.IPv4(ip4[0], ip4[1], ip4[2], ip4[3]), true
+}
+
+// hexDecimal returns the value of x read as if its hex digits were decimal
+// ones, so 0x0443 is 443. It returns false if any digit is above 9
+func hexDecimal(x uint16) (int, bool) {
+	n, err := strconv.Atoi(fmt.Sprintf("%x", x))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
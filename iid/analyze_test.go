@@ -0,0 +1,89 @@
+package iid
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var AnalyzeTests = []struct {
+	name   string
+	addr   string
+	kind   Kind
+	conf   float64
+	detail string
+}{
+	{"Reserved", "2001:db8::200:5eff:fe00:5213", KindReserved, 1, "RFC6543"},
+	{"ReservedAnycast", "2001:db8::", KindReserved, 1, "RFC4291"},
+	{"ISATAP", "2001:db8::5efe:c000:201", KindISATAP, 1, "192.0.2.1"},
+	{"ISATAPGlobal", "2001:db8::200:5efe:c000:201", KindISATAP, 1, "192.0.2.1"},
+	{"EUI64", "2001:db8::9b88:77ff:fe66:5544", KindEUI64, 0.9, "99:88:77:66:55:44"},
+	{"PortDecimal", "2001:db8::443", KindEmbeddedPort, 0.8, "443"},
+	{"PortDNS", "2001:db8::53", KindEmbeddedPort, 0.8, "53"},
+	{"PortNumeric", "2001:db8::1bb", KindEmbeddedPort, 0.6, "443"},
+	{"LowByte", "2001:db8::1", KindLowByte, 1, ""},
+	{"LowByte2", "2001:db8::2", KindLowByte, 1, ""},
+	{"LowWord", "2001:db8::1234", KindLowByte, 0.9, ""},
+	{"LowTwoWords", "2001:db8::1:1", KindLowByte, 0.6, ""},
+	{"IPv4Decimal", "2001:db8::192:168:1:1", KindEmbeddedIPv4, 0.9, "192.168.1.1"},
+	{"IPv4Hex", "2001:db8::c0a8:101", KindEmbeddedIPv4, 0.8, "192.168.1.1"},
+	{"Wordy", "2001:db8::dead:beef:cafe:1234", KindWordy, 0.9, "dead beef cafe"},
+	{"WordyOne", "2001:db8::1234:5678:9abc:f00d", KindWordy, 0.6, "f00d"},
+	{"Randomized", "2001:db8::c6fa:ba02:41ab:282c", KindRandomized, 0.875, ""},
+}
+
+func TestAnalyzeIID(t *testing.T) {
+	for _, tt := range AnalyzeTests {
+		a, err := AnalyzeIID(net.ParseIP(tt.addr))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+			continue
+		}
+		if a.Kind != tt.kind {
+			t.Errorf("%s: expected %s got %s", tt.name, tt.kind, a.Kind)
+			continue
+		}
+		if a.Confidence != tt.conf {
+			t.Errorf("%s: expected confidence %v got %v", tt.name, tt.conf, a.Confidence)
+		}
+
+		detail := ""
+		switch a.Kind {
+		case KindReserved:
+			detail = a.Reservations[0].RFC
+		case KindISATAP, KindEmbeddedIPv4:
+			detail = a.IP4.String()
+		case KindEUI64:
+			detail = a.HardwareAddr.String()
+		case KindEmbeddedPort:
+			detail = strconv.Itoa(a.Port)
+		case KindWordy:
+			for i, w := range a.Words {
+				if i > 0 {
+					detail += " "
+				}
+				detail += w
+			}
+		}
+		if detail != tt.detail {
+			t.Errorf("%s: expected detail '%s' got '%s'", tt.name, tt.detail, detail)
+		}
+	}
+}
+
+func TestAnalyzeIID_NotIP6(t *testing.T) {
+	if _, err := AnalyzeIID(net.ParseIP("192.168.1.1")); err != iplib.ErrNotIP6 {
+		t.Errorf("expected ErrNotIP6 got '%v'", err)
+	}
+}
+
+func TestKind_String(t *testing.T) {
+	if s := KindEmbeddedPort.String(); s != "embedded-port" {
+		t.Errorf("expected 'embedded-port' got '%s'", s)
+	}
+	if s := Kind(99).String(); s != "Kind(99)" {
+		t.Errorf("expected 'Kind(99)' got '%s'", s)
+	}
+}
@@ -0,0 +1,250 @@
+package iid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	ErrBadCGACollisionCount = errors.New("CGA collision count must be 0, 1 or 2")
+	ErrBadCGAParams         = errors.New("CGA parameters are malformed")
+	ErrBadCGASec            = errors.New("CGA Sec parameter must be between 0 and 7")
+	ErrBadPublicKey         = errors.New("public key is not a DER-encoded SubjectPublicKeyInfo")
+	ErrCGAHashMismatch      = errors.New("address does not match the hash of its CGA parameters")
+	ErrCGAPrefixMismatch    = errors.New("address prefix does not match its CGA parameters")
+)
+
+// CGAParams is the CGA Parameters data structure from RFC3972 section 3,
+// the input to the hashes that produce a Cryptographically Generated Address
+type CGAParams struct {
+	// Modifier is the 128bit value found by the modifier search, which
+	// makes Hash2 start with 16*Sec zero bits
+	Modifier [16]byte
+
+	// Prefix is the 64bit subnet prefix of the address
+	Prefix [8]byte
+
+	// CollisionCount is incremented each time duplicate address detection
+	// fails, it may only be 0, 1 or 2
+	CollisionCount uint8
+
+	// PublicKey is the DER-encoded ASN.1 SubjectPublicKeyInfo of the owner
+	// of the address, as returned by x509.MarshalPKIXPublicKey()
+	PublicKey []byte
+
+	// Extensions holds any optional extension fields, already encoded as
+	// RFC3972 describes
+	Extensions []byte
+}
+
+// GenerateCGA returns a Cryptographically Generated Address in the /64 of
+// the supplied v6 IP along with the parameters needed to verify it, using
+// the algorithm in RFC3972 section 4. pubKey must be a DER-encoded
+// SubjectPublicKeyInfo. The modifier search starts from a random value read
+// from r, or from crypto/rand if r is nil, and is repeated until Hash2 begins
+// with 16*sec zero bits. Each increment of sec multiplies the expected work
+// by 65536, so values above 1 may take a very long time indeed.
+//
+// collisionCount should be 0 unless the address was found to be a duplicate,
+// although if that happens it is far cheaper to increment the CollisionCount
+// of the returned CGAParams and call Addr() than to search again
+func GenerateCGA(ip net.IP, pubKey []byte, sec, collisionCount uint8, r io.Reader) (net.IP, CGAParams, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return nil, CGAParams{}, iplib.ErrNotIP6
+	}
+	if sec > 7 {
+		return nil, CGAParams{}, ErrBadCGASec
+	}
+	if collisionCount > 2 {
+		return nil, CGAParams{}, ErrBadCGACollisionCount
+	}
+	if _, err := derLength(pubKey); err != nil {
+		return nil, CGAParams{}, err
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+
+	p := CGAParams{CollisionCount: collisionCount}
+	copy(p.Prefix[:], ip[:8])
+	p.PublicKey = make([]byte, len(pubKey))
+	copy(p.PublicKey, pubKey)
+
+	if _, err := io.ReadFull(r, p.Modifier[:]); err != nil {
+		return nil, CGAParams{}, err
+	}
+	for !hash2Zero(p.hash2(), sec) {
+		incrementModifier(&p.Modifier)
+	}
+
+	cga, err := p.Addr(sec)
+	if err != nil {
+		return nil, CGAParams{}, err
+	}
+	return cga, p, nil
+}
+
+// VerifyCGA checks that the supplied address was generated from the supplied
+// parameters as described in RFC3972 section 5, returning nil if so
+func VerifyCGA(ip net.IP, p CGAParams) error {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return iplib.ErrNotIP6
+	}
+	if p.CollisionCount > 2 {
+		return ErrBadCGACollisionCount
+	}
+	if !bytes.Equal(ip[:8], p.Prefix[:]) {
+		return ErrCGAPrefixMismatch
+	}
+
+	hash1 := p.hash1()
+	hash1[0] &^= 0xe3
+	iid := make([]byte, 8)
+	copy(iid, ip[8:])
+	iid[0] &^= 0xe3
+	if !bytes.Equal(hash1, iid) {
+		return ErrCGAHashMismatch
+	}
+
+	if !hash2Zero(p.hash2(), GetCGASec(ip)) {
+		return ErrCGAHashMismatch
+	}
+	return nil
+}
+
+// GetCGASec returns the Sec parameter encoded in the 3 leftmost bits of the
+// IID of a CGA
+func GetCGASec(ip net.IP) uint8 {
+	if len(ip) != net.IPv6len {
+		return 0
+	}
+	return ip[8] >> 5
+}
+
+// ParseCGAParams reads a CGA Parameters data structure as it appears on the
+// wire, for example in a SEND CGA option. The length of the public key is
+// taken from its DER encoding and anything following it is treated as
+// extension fields
+func ParseCGAParams(b []byte) (CGAParams, error) {
+	if len(b) < 25 {
+		return CGAParams{}, ErrBadCGAParams
+	}
+
+	p := CGAParams{CollisionCount: b[24]}
+	copy(p.Modifier[:], b[0:16])
+	copy(p.Prefix[:], b[16:24])
+
+	l, err := derLength(b[25:])
+	if err != nil {
+		return CGAParams{}, err
+	}
+	p.PublicKey = copyBytes(b[25 : 25+l])
+	if len(b) > 25+l {
+		p.Extensions = copyBytes(b[25+l:])
+	}
+	return p, nil
+}
+
+// Addr returns the address described by the parameters with the supplied
+// Sec value encoded in it. It does not perform a modifier search, so unless
+// the Modifier was found by GenerateCGA() for the same Sec the result will
+// not pass VerifyCGA()
+func (p CGAParams) Addr(sec uint8) (net.IP, error) {
+	if sec > 7 {
+		return nil, ErrBadCGASec
+	}
+	if p.CollisionCount > 2 {
+		return nil, ErrBadCGACollisionCount
+	}
+
+	cga := make(net.IP, 16)
+	copy(cga, p.Prefix[:])
+	copy(cga[8:], p.hash1())
+	cga[8] = cga[8]&^0xe3 | sec<<5
+	return cga, nil
+}
+
+// Marshal returns the parameters in their wire format: modifier, subnet
+// prefix, collision count, public key and extension fields concatenated
+func (p CGAParams) Marshal() []byte {
+	b := make([]byte, 0, 25+len(p.PublicKey)+len(p.Extensions))
+	b = append(b, p.Modifier[:]...)
+	b = append(b, p.Prefix[:]...)
+	b = append(b, p.CollisionCount)
+	b = append(b, p.PublicKey...)
+	return append(b, p.Extensions...)
+}
+
+// hash1 returns the leftmost 64bits of the SHA-1 hash of the parameters
+func (p CGAParams) hash1() []byte {
+	h := sha1.Sum(p.Marshal())
+	return h[:8]
+}
+
+// hash2 returns the leftmost 112bits of the SHA-1 hash of the parameters
+// with the prefix and collision count set to zero
+func (p CGAParams) hash2() []byte {
+	p.Prefix = [8]byte{}
+	p.CollisionCount = 0
+	h := sha1.Sum(p.Marshal())
+	return h[:14]
+}
+
+func hash2Zero(hash2 []byte, sec uint8) bool {
+	for _, b := range hash2[:2*int(sec)] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func incrementModifier(m *[16]byte) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i]++
+		if m[i] != 0 {
+			return
+		}
+	}
+}
+
+// derLength returns the length of the DER SEQUENCE at the start of b,
+// including its tag and length octets
+func derLength(b []byte) (int, error) {
+	if len(b) < 2 || b[0] != 0x30 {
+		return 0, ErrBadPublicKey
+	}
+	if b[1] < 0x80 {
+		l := 2 + int(b[1])
+		if l > len(b) {
+			return 0, ErrBadPublicKey
+		}
+		return l, nil
+	}
+
+	n := int(b[1] & 0x7f)
+	if n == 0 || n > 4 || len(b) < 2+n {
+		return 0, ErrBadPublicKey
+	}
+	l := 0
+	for _, c := range b[2 : 2+n] {
+		l = l<<8 | int(c)
+	}
+	l += 2 + n
+	if l > len(b) {
+		return 0, ErrBadPublicKey
+	}
+	return l, nil
+}
+
+func copyBytes(b []byte) []byte {
+	xb := make([]byte, len(b))
+	copy(xb, b)
+	return xb
+}
@@ -0,0 +1,199 @@
+package iid
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var cgaPrefix = net.ParseIP("2001:db8:1:2::")
+
+func cgaPublicKey(t *testing.T) []byte {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %s", err)
+	}
+	return der
+}
+
+func TestGenerateCGA(t *testing.T) {
+	pub := cgaPublicKey(t)
+	for _, sec := range []uint8{0, 1} {
+		for _, cc := range []uint8{0, 2} {
+			cga, p, err := GenerateCGA(cgaPrefix, pub, sec, cc, nil)
+			if err != nil {
+				t.Fatalf("sec %d cc %d: unexpected error: %s", sec, cc, err)
+			}
+			if !bytes.Equal(cga[:8], cgaPrefix[:8]) {
+				t.Errorf("sec %d cc %d: address %s not in prefix", sec, cc, cga)
+			}
+			if GetCGASec(cga) != sec {
+				t.Errorf("sec %d cc %d: address encodes sec %d", sec, cc, GetCGASec(cga))
+			}
+			if cga[8]&0x03 != 0 {
+				t.Errorf("sec %d cc %d: u and g bits are not zero in %s", sec, cc, cga)
+			}
+			if p.CollisionCount != cc {
+				t.Errorf("sec %d cc %d: params have collision count %d", sec, cc, p.CollisionCount)
+			}
+			if err := VerifyCGA(cga, p); err != nil {
+				t.Errorf("sec %d cc %d: failed to verify: %s", sec, cc, err)
+			}
+		}
+	}
+}
+
+func TestGenerateCGA_Deterministic(t *testing.T) {
+	pub := cgaPublicKey(t)
+	modifier := bytes.Repeat([]byte{0x5a}, 16)
+
+	a, pa, err := GenerateCGA(cgaPrefix, pub, 0, 0, bytes.NewReader(modifier))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, pb, err := GenerateCGA(cgaPrefix, pub, 0, 0, bytes.NewReader(modifier))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !a.Equal(b) || pa.Modifier != pb.Modifier {
+		t.Errorf("same inputs produced different CGAs: %s and %s", a, b)
+	}
+	if !bytes.Equal(pa.Modifier[:], modifier) {
+		t.Errorf("sec 0 should not change the modifier, got %x", pa.Modifier)
+	}
+}
+
+var CGAErrorTests = []struct {
+	name string
+	ip   net.IP
+	pub  []byte
+	sec  uint8
+	cc   uint8
+	err  error
+}{
+	{"NotIP6", net.ParseIP("192.168.1.1"), nil, 0, 0, iplib.ErrNotIP6},
+	{"BadSec", cgaPrefix, nil, 8, 0, ErrBadCGASec},
+	{"BadCollisionCount", cgaPrefix, nil, 0, 3, ErrBadCGACollisionCount},
+	{"EmptyKey", cgaPrefix, []byte{}, 0, 0, ErrBadPublicKey},
+	{"NotSequence", cgaPrefix, []byte{0x04, 0x02, 0x00, 0x00}, 0, 0, ErrBadPublicKey},
+	{"TruncatedKey", cgaPrefix, []byte{0x30, 0x82, 0x01, 0x00, 0x00}, 0, 0, ErrBadPublicKey},
+}
+
+func TestGenerateCGA_Errors(t *testing.T) {
+	for _, tt := range CGAErrorTests {
+		pub := tt.pub
+		if pub == nil {
+			pub = cgaPublicKey(t)
+		}
+		_, _, err := GenerateCGA(tt.ip, pub, tt.sec, tt.cc, nil)
+		if err != tt.err {
+			t.Errorf("%s: expected '%v' got '%v'", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestVerifyCGA(t *testing.T) {
+	cga, p, err := GenerateCGA(cgaPrefix, cgaPublicKey(t), 1, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	other := make(net.IP, 16)
+	copy(other, cga)
+	other[7] ^= 0x01
+	if err := VerifyCGA(other, p); err != ErrCGAPrefixMismatch {
+		t.Errorf("changed prefix: expected ErrCGAPrefixMismatch got '%v'", err)
+	}
+
+	copy(other, cga)
+	other[15] ^= 0x01
+	if err := VerifyCGA(other, p); err != ErrCGAHashMismatch {
+		t.Errorf("changed IID: expected ErrCGAHashMismatch got '%v'", err)
+	}
+
+	copy(other, cga)
+	other[8] |= 0x03
+	if err := VerifyCGA(other, p); err != nil {
+		t.Errorf("u and g bits should be ignored, got '%v'", err)
+	}
+
+	copy(other, cga)
+	other[8] = other[8]&^0xe0 | 7<<5
+	if err := VerifyCGA(other, p); err != ErrCGAHashMismatch {
+		t.Errorf("raised sec: expected ErrCGAHashMismatch got '%v'", err)
+	}
+
+	xp := p
+	xp.CollisionCount = 1
+	if err := VerifyCGA(cga, xp); err != ErrCGAHashMismatch {
+		t.Errorf("changed collision count: expected ErrCGAHashMismatch got '%v'", err)
+	}
+
+	xp.CollisionCount = 3
+	if err := VerifyCGA(cga, xp); err != ErrBadCGACollisionCount {
+		t.Errorf("bad collision count: expected ErrBadCGACollisionCount got '%v'", err)
+	}
+}
+
+func TestCGAParams_Addr(t *testing.T) {
+	cga, p, err := GenerateCGA(cgaPrefix, cgaPublicKey(t), 1, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	p.CollisionCount++
+	next, err := p.Addr(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if next.Equal(cga) {
+		t.Errorf("incrementing the collision count did not change the address")
+	}
+	if err := VerifyCGA(next, p); err != nil {
+		t.Errorf("failed to verify after collision: %s", err)
+	}
+}
+
+func TestParseCGAParams(t *testing.T) {
+	_, p, err := GenerateCGA(cgaPrefix, cgaPublicKey(t), 0, 1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	p.Extensions = []byte{0x00, 0x01, 0x00, 0x02, 0xaa, 0xbb}
+
+	b := p.Marshal()
+	if len(b) != 25+len(p.PublicKey)+len(p.Extensions) {
+		t.Fatalf("marshalled length %d is wrong", len(b))
+	}
+
+	xp, err := ParseCGAParams(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if xp.Modifier != p.Modifier || xp.Prefix != p.Prefix || xp.CollisionCount != p.CollisionCount {
+		t.Errorf("fixed fields did not survive a round trip")
+	}
+	if !bytes.Equal(xp.PublicKey, p.PublicKey) {
+		t.Errorf("public key did not survive a round trip")
+	}
+	if !bytes.Equal(xp.Extensions, p.Extensions) {
+		t.Errorf("expected extensions %x got %x", p.Extensions, xp.Extensions)
+	}
+
+	if _, err := ParseCGAParams(b[:24]); err != ErrBadCGAParams {
+		t.Errorf("short input: expected ErrBadCGAParams got '%v'", err)
+	}
+	if _, err := ParseCGAParams(b[:30]); err != ErrBadPublicKey {
+		t.Errorf("truncated key: expected ErrBadPublicKey got '%v'", err)
+	}
+}
@@ -0,0 +1,65 @@
+package iid
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+
+	"github.com/kenits/iplib"
+)
+
+// DHCPv6Retries is the number of times MakeDHCPv6Addr() will rehash when it
+// lands on an address it may not assign
+const DHCPv6Retries = 16
+
+var (
+	ErrBadDUID      = errors.New("DUID must be between 1 and 130 bytes")
+	ErrNoDHCPv6Addr = errors.New("no assignable address found in pool")
+)
+
+// MakeDHCPv6Addr returns an address for a DHCPv6 client, identified by its
+// DUID and the IAID of one of its identity associations, within the supplied
+// v6 pool. The same inputs always produce the same address, so a server can
+// assign addresses without keeping any state. The host bits of the pool are
+// filled from the SHA256 hash of the DUID, IAID, a counter and an optional
+// secret, which keeps the assignments from being predicted by anyone who
+// does not hold it.
+//
+// If the address is the network (subnet-router anycast) address of the pool
+// or its IID is in the IANA reserved list the counter is incremented and the
+// inputs rehashed, up to DHCPv6Retries times before ErrNoDHCPv6Addr is
+// returned. Different clients may be given the same address, particularly in
+// small pools, so the server must still check for conflicts
+func MakeDHCPv6Addr(pool iplib.Net, duid []byte, iaid uint32, secret []byte) (net.IP, error) {
+	ones, err := prefixLength(pool)
+	if err != nil {
+		return nil, err
+	}
+	if len(duid) < 1 || len(duid) > 130 {
+		return nil, ErrBadDUID
+	}
+
+	network := pool.IP.Mask(pool.Mask)
+	bs := make([]byte, 8)
+	binary.BigEndian.PutUint32(bs, iaid)
+
+	for counter := uint32(0); counter <= DHCPv6Retries; counter++ {
+		binary.BigEndian.PutUint32(bs[4:], counter)
+
+		f := sha256.New()
+		f.Write(duid)
+		f.Write(bs)
+		f.Write(secret)
+
+		ip := make(net.IP, 16)
+		copy(ip, network)
+		fillIID(ip, f.Sum(nil), 128-ones)
+
+		if ip.Equal(network) || !pool.Contains(ip) || len(GetReservationsForIP(ip)) > 0 {
+			continue
+		}
+		return ip, nil
+	}
+	return nil, ErrNoDHCPv6Addr
+}
@@ -0,0 +1,107 @@
+package iid
+
+import (
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	dhcpDUID   = []byte{0x00, 0x01, 0x00, 0x01, 0x1c, 0x39, 0xcf, 0x88, 0x08, 0x00, 0x27, 0xfe, 0x8f, 0x95}
+	dhcpSecret = []byte("secret")
+)
+
+var DHCPv6Tests = []struct {
+	name string
+	cidr string
+	err  error
+}{
+	{"Pool48", "2001:db8:1111::/48", nil},
+	{"Pool64", "2001:db8:1111:2222::/64", nil},
+	{"Pool80", "2001:db8:1111:2222:3333::/80", nil},
+	{"Pool112", "2001:db8:1111:2222:3333:4444:5555:0/112", nil},
+	{"Pool120", "2001:db8::100/120", nil},
+	{"Pool128", "2001:db8::1/128", ErrPrefixTooLong},
+	{"NotIP6", "192.168.0.0/16", iplib.ErrNotIP6},
+}
+
+func TestMakeDHCPv6Addr(t *testing.T) {
+	for _, tt := range DHCPv6Tests {
+		_, pool, _ := iplib.ParseCIDR(tt.cidr)
+		out, err := MakeDHCPv6Addr(pool, dhcpDUID, 1, dhcpSecret)
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !pool.Contains(out) {
+			t.Errorf("%s: %s is not in %s", tt.name, out, tt.cidr)
+		}
+		if out.Equal(pool.IP) {
+			t.Errorf("%s: got the network address", tt.name)
+		}
+
+		again, _ := MakeDHCPv6Addr(pool, dhcpDUID, 1, dhcpSecret)
+		if !again.Equal(out) {
+			t.Errorf("%s: not deterministic, got %s then %s", tt.name, out, again)
+		}
+		other, _ := MakeDHCPv6Addr(pool, dhcpDUID, 2, dhcpSecret)
+		if other.Equal(out) {
+			t.Errorf("%s: different IAIDs got the same address %s", tt.name, out)
+		}
+		xsecret, _ := MakeDHCPv6Addr(pool, dhcpDUID, 1, []byte("terces"))
+		if xsecret.Equal(out) {
+			t.Errorf("%s: different secrets got the same address %s", tt.name, out)
+		}
+	}
+}
+
+func TestMakeDHCPv6Addr_SkipsNetwork(t *testing.T) {
+	_, pool, _ := iplib.ParseCIDR("2001:db8::/127")
+	for iaid := uint32(0); iaid < 32; iaid++ {
+		out, err := MakeDHCPv6Addr(pool, dhcpDUID, iaid, nil)
+		if err != nil {
+			t.Fatalf("iaid %d: unexpected error: %s", iaid, err)
+		}
+		if out.String() != "2001:db8::1" {
+			t.Errorf("iaid %d: expected 2001:db8::1 got %s", iaid, out)
+		}
+	}
+}
+
+func TestMakeDHCPv6Addr_SkipsReserved(t *testing.T) {
+	_, pool, _ := iplib.ParseCIDR("2001:db8:1111:2222::/64")
+	first, _ := MakeDHCPv6Addr(pool, dhcpDUID, 1, dhcpSecret)
+
+	saved := Registry
+	defer func() { Registry = saved }()
+	Registry = append([]*Reservation{{first[8:], first[8:], "Test", "RFC0"}}, saved...)
+
+	out, err := MakeDHCPv6Addr(pool, dhcpDUID, 1, dhcpSecret)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out.Equal(first) || !pool.Contains(out) {
+		t.Errorf("expected a different address in the pool, got %s", out)
+	}
+
+	Registry = append([]*Reservation{{make([]byte, 8), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "Test", "RFC0"}}, saved...)
+	if _, err := MakeDHCPv6Addr(pool, dhcpDUID, 1, dhcpSecret); err != ErrNoDHCPv6Addr {
+		t.Errorf("expected ErrNoDHCPv6Addr got '%v'", err)
+	}
+}
+
+func TestMakeDHCPv6Addr_BadDUID(t *testing.T) {
+	_, pool, _ := iplib.ParseCIDR("2001:db8::/64")
+	if _, err := MakeDHCPv6Addr(pool, []byte{}, 1, nil); err != ErrBadDUID {
+		t.Errorf("expected ErrBadDUID got '%v'", err)
+	}
+	if _, err := MakeDHCPv6Addr(pool, make([]byte, 131), 1, nil); err != ErrBadDUID {
+		t.Errorf("expected ErrBadDUID got '%v'", err)
+	}
+	if _, err := MakeDHCPv6Addr(pool, make([]byte, 130), 1, nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
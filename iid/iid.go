@@ -56,6 +56,7 @@ const (
 
 var (
 	ErrIIDAddressCollision = errors.New("proposed IID collides with IANA reserved IID list")
+	ErrNotEUI64            = errors.New("IID is not derived from a 48bit hardware address")
 )
 
 // Registry holds the aggregated network list from IANA's "Reserved IPv6
@@ -129,7 +130,8 @@ func init() {
 // local storage. This variable provides the velocity to the entire algorithm
 // and should be incremented after each use. There is no guarantee that a
 // generated address wont accidentally fall within the range of reserved IPv6
-// IIDs and, should this happen, an ErrIIDAddressCollision will be returned.
+// IIDs, or be the Subnet-Router anycast address of its prefix, and should
+// this happen an ErrIIDAddressCollision will be returned.
 // This is harmless and if it happens counter should be incremented and the
 // function called again
 //
@@ -142,13 +144,18 @@ func init() {
 //
 // htype: a crypto.Hash function to use when generating the IID.
 //
-// scope: the scope of the IID
+// scope: the scope of the IID. As RFC7217 specifies the IID is taken from
+// the hash unmodified, so this has no effect unless WithScopeBit() is given
+//
+// opts: any Options, see WithIIDLength(), WithScopeBit() and WithRFC7136()
 //
 // NOTE that MD5 is specifically prohibited for being too easily guessable.
 //
 // NOTE that unless you use sha256 you will need to import the hash function
 // you intend to use, (e.g. import _ "crypto/sha512")
-func GenerateRFC7217Addr(ip net.IP, hw net.HardwareAddr, counter int64, netid, secret []byte, htype crypto.Hash, scope Scope) (net.IP, error) {
+func GenerateRFC7217Addr(ip net.IP, hw net.HardwareAddr, counter int64, netid, secret []byte, htype crypto.Hash, scope Scope, opts ...Option) (net.IP, error) {
+	o := newOptions(opts)
+
 	bs := make([]byte, 8)
 	binary.LittleEndian.PutUint64(bs, uint64(counter))
 
@@ -163,32 +170,57 @@ func GenerateRFC7217Addr(ip net.IP, hw net.HardwareAddr, counter int64, netid, s
 
 	f.Write(bs)
 	rid := f.Sum(nil)
-	rid = setScopeBit(rid, scope)
+	if o.iidLen < 1 || o.iidLen > 128 || o.iidLen > len(rid)*8 {
+		return nil, ErrBadIIDLength
+	}
 
-	copy(ipiid[8:], rid[0:8])
+	fillIID(ipiid, rid, o.iidLen)
+	if o.iidLen == 64 && o.scopeBit && !o.rfc7136 {
+		setScopeBit(ipiid, scope)
+	}
 
-	if r := GetReservationsForIP(ipiid); r != nil {
+	if r := GetReservationsForIP(ipiid); len(r) > 0 || isZeroIID(ipiid, o.iidLen) {
 		return nil, ErrIIDAddressCollision
 	}
 
 	return ipiid, nil
 }
 
-// GetReservationsForIP returns a list of any IANA reserved networks that
-// the supplied IP is part of
-func GetReservationsForIP(ip net.IP) *Reservation {
-	if iplib.EffectiveVersion(ip) != 6 {
-		return nil
+// GetReservationsForIP returns a list of any IANA reserved IIDs that the
+// final 64bits of the supplied IP are part of. If the IP is not a 16-byte v6
+// address the list will be empty
+func GetReservationsForIP(ip net.IP) []*Reservation {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return []*Reservation{}
 	}
-	for _, r := range Registry {
-		f := bytes.Compare(ip[8:], r.FirstRes)
-		l := bytes.Compare(ip[8:], r.LastRes)
+	return GetReservationsForRange(ip[8:], ip[8:])
+}
+
+// GetReservationsForNetwork returns a list of any IANA reserved IIDs that
+// fall within the supplied v6 network. A network of /64 or shorter contains
+// every possible IID and so every reservation. If the network is not v6 the
+// list will be empty
+func GetReservationsForNetwork(n iplib.Net) []*Reservation {
+	if n.Version() != 6 || len(n.IP) != net.IPv6len {
+		return []*Reservation{}
+	}
+	return GetReservationsForRange(n.FirstAddress()[8:], n.LastAddress()[8:])
+}
 
-		if f >= 0 && l <= 0 {
-			return r
+// GetReservationsForRange returns a list of any IANA reserved IIDs that fall
+// within the range of IIDs from first to last, inclusive. Both must be
+// 8 bytes long, otherwise the list will be empty
+func GetReservationsForRange(first, last []byte) []*Reservation {
+	reservations := []*Reservation{}
+	if len(first) != 8 || len(last) != 8 {
+		return reservations
+	}
+	for _, r := range Registry {
+		if bytes.Compare(first, r.LastRes) <= 0 && bytes.Compare(last, r.FirstRes) >= 0 {
+			reservations = append(reservations, r)
 		}
 	}
-	return nil
+	return reservations
 }
 
 // MakeEUI64Addr takes an IPv6 address, a hardware MAC address and a scope as
@@ -205,19 +237,45 @@ func GetReservationsForIP(ip net.IP) *Reservation {
 //
 // * if the address is 48 bits, the octets 0xFFFE are inserted in the middle
 // of the address to pad it to 64 bits
-func MakeEUI64Addr(ip net.IP, hw net.HardwareAddr, scope Scope) net.IP {
+//
+// The behavior can be changed with Options, see WithRFC7136(),
+// WithIIDLength() and WithStableByDefault()
+func MakeEUI64Addr(ip net.IP, hw net.HardwareAddr, scope Scope, opts ...Option) net.IP {
 	tag := []byte{0xff, 0xfe}
+	o := newOptions(opts)
 
 	if iplib.EffectiveVersion(ip) != 6 {
 		return nil
 	}
 
+	if o.secret != nil {
+		for counter := int64(0); counter <= IDGenRetries; counter++ {
+			addr, err := GenerateRFC7217Addr(ip, hw, counter, []byte{}, o.secret, crypto.SHA256, scope, opts...)
+			if err == ErrIIDAddressCollision {
+				continue
+			}
+			return addr
+		}
+		return nil
+	}
+
 	if len(hw) < 6 || len(hw) > 8 {
 		return nil
 	}
 
+	if o.iidLen < 64 || o.iidLen > 128 {
+		return nil
+	}
+
+	if o.rfc7136 {
+		scope = ScopeInvert
+	}
+
 	eui64 := make([]byte, 16)
 	copy(eui64, ip)
+	if o.iidLen != 64 {
+		zeroIID(eui64, o.iidLen)
+	}
 
 	hwi := make([]byte, len(hw))
 	copy(hwi, hw)
@@ -231,14 +289,69 @@ func MakeEUI64Addr(ip net.IP, hw net.HardwareAddr, scope Scope) net.IP {
 }
 
 
+// IsEUI64Addr returns true if the IID of the supplied v6 address appears to
+// have been built from a 48bit hardware address by MakeEUI64Addr() or an
+// equivalent, which is to say it has 0xFFFE in its 4th and 5th octets. IIDs
+// built from 64bit hardware addresses carry no such marker and cannot be
+// detected
+func IsEUI64Addr(ip net.IP) bool {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return false
+	}
+	return ip[11] == 0xff && ip[12] == 0xfe
+}
+
+// GetEUI64HardwareAddr is the reverse of MakeEUI64Addr(), it returns the
+// 48bit hardware address embedded in the IID of the supplied v6 address,
+// or ErrNotEUI64 if IsEUI64Addr() is false. The scope should be the one the
+// address was built with and the 'X' bit is restored as follows:
+//
+// * ScopeInvert inverts the bit again, recovering any hardware address
+//
+// * ScopeGlobal clears the bit, recovering a universally administered
+// hardware address
+//
+// * ScopeLocal sets the bit, recovering a locally administered hardware
+// address
+//
+// * ScopeNone leaves the bit alone
+func GetEUI64HardwareAddr(ip net.IP, scope Scope) (net.HardwareAddr, error) {
+	if !IsEUI64Addr(ip) {
+		return nil, ErrNotEUI64
+	}
+
+	hw := make(net.HardwareAddr, 6)
+	copy(hw, ip[8:11])
+	copy(hw[3:], ip[13:16])
+
+	switch scope {
+	case ScopeInvert:
+		hw[0] ^= 1 << 1
+	case ScopeGlobal:
+		hw[0] &^= 1 << 1
+	case ScopeLocal:
+		hw[0] |= 1 << 1
+	}
+	return hw, nil
+}
+
+// LeaksHardwareAddr returns true if the supplied v6 address exposes the
+// hardware address of its interface: its IID is EUI-64 derived and has the
+// 'u' bit set, meaning the embedded address is universally administered and
+// so identifies the device wherever it goes. Addresses built from locally
+// administered, for example randomized, hardware addresses are not flagged
+func LeaksHardwareAddr(ip net.IP) bool {
+	return IsEUI64Addr(ip) && ip[8]&(1<<1) != 0
+}
+
 // MakeOpaqueAddr offers one implementation of RFC7217's algorithm for
 // generating a "semantically opaque interface identifier". The caller must
 // supply a counter and secret and MAY supply an additional "netid".
 // Ultimately this function calls GenerateRFC7217Addr() with scope set to
 // "global" and an htype of SHA256, but please see the documentation in that
 // function for an explanation of all the input fields
-func MakeOpaqueAddr(ip net.IP, hw net.HardwareAddr, counter int64, netid, secret []byte) (net.IP, error) {
-	return GenerateRFC7217Addr(ip, hw, counter, netid, secret, crypto.SHA256, ScopeGlobal)
+func MakeOpaqueAddr(ip net.IP, hw net.HardwareAddr, counter int64, netid, secret []byte, opts ...Option) (net.IP, error) {
+	return GenerateRFC7217Addr(ip, hw, counter, netid, secret, crypto.SHA256, ScopeGlobal, opts...)
 }
 
 func setScopeBit(ip net.IP, scope Scope) net.IP {
@@ -94,63 +94,184 @@ func TestGenerateRFC7217Addr(t *testing.T) {
 }
 
 var IPTests = []struct {
-	name     string
-	address  string
-	res      bool
-	rfc      string
+	name    string
+	address string
+	count   int
+	rfc     string
 }{
 	{
 		"Broken",
 		"192.168.1.1",
-		false,
+		0,
 		"",
 	},
 	{
 		"NotReserved",
 		"25:100:200::0200:5EFF:FF00:521A",
-		false,
+		0,
 		"",
 	},
 	{
 		"ReservedAnycast",
 		"::",
-		true,
+		1,
 		"RFC4291",
 	},
 	{
 		"ReservedEthernet",
 		"aaaa:bbbb:cccc:dddd:0200:5EFF:FE00:5211",
-		true,
+		1,
 		"RFC4291",
 	},
 	{
 		"ReservedProxyMobile",
 		"aaaa:bbbb:cccc:dddd:0200:5EFF:FE00:5213",
-		true,
+		1,
 		"RFC6543",
 	},
+	{
+		"ReservedSubnetAnycast",
+		"2001:db8::fdff:ffff:ffff:ff80",
+		1,
+		"RFC2526",
+	},
 }
 
 func TestGetReservationsForIP(t *testing.T) {
 	for _, tt := range IPTests {
 		ip := net.ParseIP(tt.address)
 		r := GetReservationsForIP(ip)
-		if tt.res == false {
-			if r != nil {
-				t.Errorf("%s: expected no results, got '%s'", tt.name, r.Title)
-			}
-		} else {
-			if r == nil {
-				t.Errorf("%s: got no result but one was expected", tt.name)
-			} else {
-				if r.RFC != tt.rfc {
-					t.Errorf("%s got wrong reservation, expected '%s' got %s", tt.name, tt.rfc, r.RFC)
-				}
+		if len(r) != tt.count {
+			t.Errorf("%s: expected %d results, got %d", tt.name, tt.count, len(r))
+			continue
+		}
+		if tt.count > 0 && r[0].RFC != tt.rfc {
+			t.Errorf("%s got wrong reservation, expected '%s' got %s", tt.name, tt.rfc, r[0].RFC)
+		}
+	}
+}
+
+func TestGetReservationsForIP_Short(t *testing.T) {
+	r := GetReservationsForIP(net.IP{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0})
+	if len(r) != 0 {
+		t.Errorf("expected no results for a short IP, got %d", len(r))
+	}
+}
+
+var RangeTests = []struct {
+	name  string
+	first []byte
+	last  []byte
+	rfcs  []string
+}{
+	{
+		"Empty",
+		[]byte{0x02, 0x00, 0x5e, 0xff, 0xfd, 0x00, 0x00, 0x00},
+		[]byte{0x02, 0x00, 0x5e, 0xff, 0xfd, 0xff, 0xff, 0xff},
+		[]string{},
+	},
+	{
+		"Single",
+		[]byte{0x02, 0x00, 0x5e, 0xff, 0xfe, 0x00, 0x52, 0x13},
+		[]byte{0x02, 0x00, 0x5e, 0xff, 0xfe, 0x00, 0x52, 0x13},
+		[]string{"RFC6543"},
+	},
+	{
+		"OverlapEthernet",
+		[]byte{0x02, 0x00, 0x5e, 0xff, 0xfe, 0x00, 0x52, 0x10},
+		[]byte{0x02, 0x00, 0x5e, 0xff, 0xfe, 0x00, 0x52, 0x20},
+		[]string{"RFC4291", "RFC6543", "RFC4291"},
+	},
+	{
+		"OverlapEnd",
+		[]byte{0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xf0},
+		[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		[]string{"RFC2526"},
+	},
+	{
+		"Everything",
+		[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		[]string{"RFC4291", "RFC4291", "RFC6543", "RFC4291", "RFC2526"},
+	},
+	{
+		"BadLength",
+		[]byte{0x00, 0x00, 0x00, 0x00},
+		[]byte{0xff, 0xff, 0xff, 0xff},
+		[]string{},
+	},
+}
+
+func TestGetReservationsForRange(t *testing.T) {
+	for _, tt := range RangeTests {
+		r := GetReservationsForRange(tt.first, tt.last)
+		if len(r) != len(tt.rfcs) {
+			t.Errorf("%s: expected %d results, got %d", tt.name, len(tt.rfcs), len(r))
+			continue
+		}
+		for i, res := range r {
+			if res.RFC != tt.rfcs[i] {
+				t.Errorf("%s: result %d expected '%s' got '%s'", tt.name, i, tt.rfcs[i], res.RFC)
 			}
 		}
 	}
 }
 
+var NetworkTests = []struct {
+	name  string
+	cidr  string
+	count int
+}{
+	{
+		"V4",
+		"192.168.1.0/24",
+		0,
+	},
+	{
+		"Pool64",
+		"2001:db8::/64",
+		5,
+	},
+	{
+		"Pool48",
+		"2001:db8::/48",
+		5,
+	},
+	{
+		"AnycastOnly",
+		"2001:db8::/120",
+		1,
+	},
+	{
+		"NotReserved",
+		"2001:db8::1:0/112",
+		0,
+	},
+	{
+		"SubnetAnycast",
+		"2001:db8::fdff:ffff:ffff:ff00/120",
+		1,
+	},
+	{
+		"Ethernet",
+		"2001:db8::200:5eff:fe00:5200/120",
+		3,
+	},
+}
+
+func TestGetReservationsForNetwork(t *testing.T) {
+	for _, tt := range NetworkTests {
+		_, n, err := iplib.ParseCIDR(tt.cidr)
+		if err != nil {
+			t.Fatalf("%s: failed to parse %s: %s", tt.name, tt.cidr, err)
+		}
+		r := GetReservationsForNetwork(n)
+		if len(r) != tt.count {
+			t.Errorf("%s: expected %d results, got %d", tt.name, tt.count, len(r))
+		}
+	}
+}
+
 var EUI64Tests = []struct {
 	inaddr    string
 	hwaddr    string
@@ -243,6 +364,103 @@ func TestMakeEUI64Addr(t *testing.T) {
 	}
 }
 
+var ReverseEUI64Tests = []struct {
+	name   string
+	addr   string
+	scope  Scope
+	hwaddr string
+	leaks  bool
+	err    error
+}{
+	{
+		"NotIP6",
+		"192.168.1.1",
+		ScopeInvert,
+		"",
+		false,
+		ErrNotEUI64,
+	},
+	{
+		"NoMarker",
+		"2001:db8:1111:2222:bbaa:ccdd:ddcc:aabb",
+		ScopeInvert,
+		"",
+		false,
+		ErrNotEUI64,
+	},
+	{
+		"Invert",
+		"2001:db8:1111:2222:b9aa:ccff:fedd:eeff",
+		ScopeInvert,
+		"bb:aa:cc:dd:ee:ff",
+		false,
+		nil,
+	},
+	{
+		"InvertUniversal",
+		"2001:db8:1111:2222:9b88:77ff:fe66:5544",
+		ScopeInvert,
+		"99:88:77:66:55:44",
+		true,
+		nil,
+	},
+	{
+		"Global",
+		"2001:db8:1111:2222:9b88:77ff:fe66:5544",
+		ScopeGlobal,
+		"99:88:77:66:55:44",
+		true,
+		nil,
+	},
+	{
+		"Local",
+		"2001:db8:1111:2222:b9aa:ccff:fedd:eeff",
+		ScopeLocal,
+		"bb:aa:cc:dd:ee:ff",
+		false,
+		nil,
+	},
+	{
+		"None",
+		"2001:db8:1111:2222:9988:77ff:fe66:5544",
+		ScopeNone,
+		"99:88:77:66:55:44",
+		false,
+		nil,
+	},
+}
+
+func TestGetEUI64HardwareAddr(t *testing.T) {
+	for _, tt := range ReverseEUI64Tests {
+		ip := net.ParseIP(tt.addr)
+		hw, err := GetEUI64HardwareAddr(ip, tt.scope)
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err == nil && hw.String() != tt.hwaddr {
+			t.Errorf("%s: expected %s got %s", tt.name, tt.hwaddr, hw)
+		}
+		if IsEUI64Addr(ip) != (tt.err == nil) {
+			t.Errorf("%s: expected IsEUI64Addr %t", tt.name, tt.err == nil)
+		}
+		if LeaksHardwareAddr(ip) != tt.leaks {
+			t.Errorf("%s: expected LeaksHardwareAddr %t", tt.name, tt.leaks)
+		}
+	}
+}
+
+func TestGetEUI64HardwareAddr_RoundTrip(t *testing.T) {
+	ip := net.ParseIP("2001:db8:1111:2222::")
+	for _, s := range []string{"00:1b:63:84:45:e6", "02:42:ac:11:00:02", "99:88:77:66:55:44", "bb:aa:cc:dd:ee:ff"} {
+		hw, _ := net.ParseMAC(s)
+		out, err := GetEUI64HardwareAddr(MakeEUI64Addr(ip, hw, ScopeInvert), ScopeInvert)
+		if err != nil || out.String() != s {
+			t.Errorf("%s: round trip produced %s, %v", s, out, err)
+		}
+	}
+}
+
 var OpaqueAddrTests = []struct {
 	netid   string
 	secret  string
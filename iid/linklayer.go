@@ -0,0 +1,108 @@
+package iid
+
+import (
+	"errors"
+	"net"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	ErrNotShortAddr = errors.New("IID is not derived from an IEEE 802.15.4 short address")
+)
+
+// shortAddrTag sits between the PAN ID and short address in an IID built
+// from an IEEE 802.15.4 short address
+var shortAddrTag = []byte{0x00, 0xff, 0xfe, 0x00}
+
+// MakeIEEE802154ShortAddr returns the supplied v6 address with its IID
+// built from a 16bit IEEE 802.15.4 short address as RFC6282 and RFC6775 do,
+// 0000:00ff:fe00:XXXX. It returns nil if the address is not v6
+func MakeIEEE802154ShortAddr(ip net.IP, short uint16) net.IP {
+	return MakeIEEE802154PANAddr(ip, 0, short)
+}
+
+// MakeIEEE802154PANAddr returns the supplied v6 address with its IID built
+// from a 16bit IEEE 802.15.4 short address and the PAN ID of the network as
+// RFC4944 section 6 describes, PPPP:00ff:fe00:XXXX. The 'u' bit, the 7th bit
+// of the PAN ID, is always cleared since a short address is not globally
+// unique. It returns nil if the address is not v6
+func MakeIEEE802154PANAddr(ip net.IP, panID, short uint16) net.IP {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return nil
+	}
+
+	xip := make(net.IP, 16)
+	copy(xip, ip[:8])
+	xip[8] = byte(panID>>8) &^ (1 << 1)
+	xip[9] = byte(panID)
+	copy(xip[10:14], shortAddrTag)
+	xip[14] = byte(short >> 8)
+	xip[15] = byte(short)
+	return xip
+}
+
+// DecodeIEEE802154Addr is the reverse of MakeIEEE802154PANAddr() and
+// MakeIEEE802154ShortAddr(), returning the PAN ID, which is zero if none was
+// used, and the short address from the IID of the supplied v6 address. The
+// 'u' bit of the PAN ID is lost during encoding and so is always zero. If the
+// IID does not have the form PPPP:00ff:fe00:XXXX ErrNotShortAddr is returned
+func DecodeIEEE802154Addr(ip net.IP) (panID, short uint16, err error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return 0, 0, iplib.ErrNotIP6
+	}
+	for i, b := range shortAddrTag {
+		if ip[10+i] != b {
+			return 0, 0, ErrNotShortAddr
+		}
+	}
+	return uint16(ip[8])<<8 | uint16(ip[9]), uint16(ip[14])<<8 | uint16(ip[15]), nil
+}
+
+// MakeBLEAddr returns the supplied v6 address with its IID built from a
+// 48bit Bluetooth LE device address as RFC7668 section 3.2.2 describes: the
+// octets 0xFFFE are inserted in the middle as for EUI-64 and the 'u' bit is
+// cleared, since the device address may be random. It returns nil if the
+// address is not v6 or the device address is not 48 bits
+func MakeBLEAddr(ip net.IP, bdaddr net.HardwareAddr) net.IP {
+	if len(ip) != net.IPv6len || len(bdaddr) != 6 {
+		return nil
+	}
+	return MakeEUI64Addr(ip, bdaddr, ScopeLocal)
+}
+
+// GetBLEDeviceAddr is the reverse of MakeBLEAddr(), returning the 48bit
+// Bluetooth LE device address from the IID of the supplied v6 address. The
+// bit that became the 'u' bit is lost during encoding and so is always zero.
+// If the IID does not contain 0xFFFE ErrNotEUI64 is returned
+func GetBLEDeviceAddr(ip net.IP) (net.HardwareAddr, error) {
+	return GetEUI64HardwareAddr(ip, ScopeGlobal)
+}
+
+// MakeInfiniBandAddr returns the supplied v6 address with its IID built from
+// the 64bit port GUID of an InfiniBand interface as RFC4391 section 8
+// describes, by inverting the 'u' bit. Either the GUID itself or the 20 byte
+// IPoIB hardware address, which ends with it, may be supplied. It returns nil
+// if the address is not v6 or the GUID is the wrong size
+func MakeInfiniBandAddr(ip net.IP, guid []byte) net.IP {
+	if len(guid) == 20 {
+		guid = guid[12:]
+	}
+	if len(ip) != net.IPv6len || len(guid) != 8 {
+		return nil
+	}
+	return MakeEUI64Addr(ip, net.HardwareAddr(guid), ScopeInvert)
+}
+
+// GetInfiniBandGUID is the reverse of MakeInfiniBandAddr(), returning the
+// 64bit port GUID from the IID of the supplied v6 address. Since there is no
+// marker in the IID any v6 address will produce a result
+func GetInfiniBandGUID(ip net.IP) ([]byte, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return nil, iplib.ErrNotIP6
+	}
+	guid := make([]byte, 8)
+	copy(guid, ip[8:])
+	guid[0] ^= 1 << 1
+	return guid, nil
+}
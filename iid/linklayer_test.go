@@ -0,0 +1,111 @@
+package iid
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var llPrefix = net.ParseIP("fe80::")
+
+var IEEE802154Tests = []struct {
+	name  string
+	panID uint16
+	short uint16
+	out   string
+	xpan  uint16
+}{
+	{"NoPAN", 0, 0x1234, "fe80::ff:fe00:1234", 0},
+	{"PAN", 0xabcd, 0x0001, "fe80::a9cd:ff:fe00:1", 0xa9cd},
+	{"PANNoUBit", 0x0102, 0xbeef, "fe80::102:ff:fe00:beef", 0x0102},
+}
+
+func TestMakeIEEE802154PANAddr(t *testing.T) {
+	for _, tt := range IEEE802154Tests {
+		out := MakeIEEE802154PANAddr(llPrefix, tt.panID, tt.short)
+		if out.String() != tt.out {
+			t.Errorf("%s: expected %s got %s", tt.name, tt.out, out)
+			continue
+		}
+
+		pan, short, err := DecodeIEEE802154Addr(out)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+			continue
+		}
+		if pan != tt.xpan || short != tt.short {
+			t.Errorf("%s: expected %04x/%04x got %04x/%04x", tt.name, tt.xpan, tt.short, pan, short)
+		}
+	}
+
+	if out := MakeIEEE802154ShortAddr(llPrefix, 0x1234); out.String() != "fe80::ff:fe00:1234" {
+		t.Errorf("ShortAddr: expected fe80::ff:fe00:1234 got %s", out)
+	}
+	if out := MakeIEEE802154ShortAddr(net.ParseIP("192.168.1.1"), 0x1234); out != nil {
+		t.Errorf("NotIP6: expected <nil> got %s", out)
+	}
+}
+
+func TestDecodeIEEE802154Addr_Errors(t *testing.T) {
+	if _, _, err := DecodeIEEE802154Addr(net.ParseIP("fe80::9b88:77ff:fe66:5544")); err != ErrNotShortAddr {
+		t.Errorf("EUI64: expected ErrNotShortAddr got '%v'", err)
+	}
+	if _, _, err := DecodeIEEE802154Addr(net.ParseIP("192.168.1.1")); err != iplib.ErrNotIP6 {
+		t.Errorf("NotIP6: expected ErrNotIP6 got '%v'", err)
+	}
+}
+
+func TestMakeBLEAddr(t *testing.T) {
+	bd, _ := net.ParseMAC("00:1a:7d:da:71:13")
+	out := MakeBLEAddr(llPrefix, bd)
+	if out.String() != "fe80::1a:7dff:feda:7113" {
+		t.Errorf("expected fe80::1a:7dff:feda:7113 got %s", out)
+	}
+	xbd, err := GetBLEDeviceAddr(out)
+	if err != nil || xbd.String() != bd.String() {
+		t.Errorf("expected %s got %s, %v", bd, xbd, err)
+	}
+
+	random, _ := net.ParseMAC("c2:1a:7d:da:71:13")
+	out = MakeBLEAddr(llPrefix, random)
+	if out.String() != "fe80::c01a:7dff:feda:7113" {
+		t.Errorf("expected the u bit cleared in fe80::c01a:7dff:feda:7113 got %s", out)
+	}
+
+	eui64, _ := net.ParseMAC("00:1a:7d:da:71:13:00:01")
+	if out := MakeBLEAddr(llPrefix, eui64); out != nil {
+		t.Errorf("expected <nil> for a 64bit address got %s", out)
+	}
+	if _, err := GetBLEDeviceAddr(net.ParseIP("fe80::1")); err != ErrNotEUI64 {
+		t.Errorf("expected ErrNotEUI64 got '%v'", err)
+	}
+}
+
+func TestMakeInfiniBandAddr(t *testing.T) {
+	guid := []byte{0x00, 0x02, 0xc9, 0x03, 0x00, 0x0b, 0x7e, 0x31}
+	want := "fe80::202:c903:b:7e31"
+
+	if out := MakeInfiniBandAddr(llPrefix, guid); out.String() != want {
+		t.Errorf("GUID: expected %s got %s", want, out)
+	}
+
+	ipoib := append([]byte{0x80, 0x00, 0x04, 0x04, 0xfe, 0x80, 0, 0, 0, 0, 0, 0}, guid...)
+	out := MakeInfiniBandAddr(llPrefix, ipoib)
+	if out.String() != want {
+		t.Errorf("IPoIB: expected %s got %s", want, out)
+	}
+
+	xguid, err := GetInfiniBandGUID(out)
+	if err != nil || !bytes.Equal(xguid, guid) {
+		t.Errorf("expected %x got %x, %v", guid, xguid, err)
+	}
+
+	if out := MakeInfiniBandAddr(llPrefix, guid[:6]); out != nil {
+		t.Errorf("expected <nil> for a short GUID got %s", out)
+	}
+	if _, err := GetInfiniBandGUID(net.ParseIP("192.168.1.1")); err != iplib.ErrNotIP6 {
+		t.Errorf("expected ErrNotIP6 got '%v'", err)
+	}
+}
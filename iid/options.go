@@ -0,0 +1,113 @@
+package iid
+
+import (
+	"errors"
+	"math/big"
+	"net"
+)
+
+var (
+	ErrBadIIDLength = errors.New("IID length must be between 1 and 128 bits and no longer than the hash")
+)
+
+// Option modifies the behavior of MakeEUI64Addr(), GenerateRFC7217Addr() and
+// MakeOpaqueAddr()
+type Option func(*options)
+
+type options struct {
+	rfc7136  bool
+	scopeBit bool
+	iidLen   int
+	secret   []byte
+}
+
+// WithRFC7136 applies RFC7136's reading of the 'u' and 'g' bits: they carry
+// no meaning in an IID, so they are not modified and the scope parameter is
+// ignored by every generator. The one exception is an EUI-64 IID, where the
+// 'u' bit is the inverted IEEE U/L bit as RFC4291 specifies, so
+// MakeEUI64Addr() behaves as if given ScopeInvert
+func WithRFC7136() Option {
+	return func(o *options) {
+		o.rfc7136 = true
+	}
+}
+
+// WithScopeBit causes GenerateRFC7217Addr() and MakeOpaqueAddr() to set the
+// 'u' bit of a 64bit IID according to the scope parameter. By default, as
+// RFC7217 specifies, the IID is taken from the hash unmodified. It is
+// ignored if WithRFC7136() is also given
+func WithScopeBit() Option {
+	return func(o *options) {
+		o.scopeBit = true
+	}
+}
+
+// WithIIDLength sets the length of the IID in bits for prefixes other than
+// /64, the IID replacing the final n bits of the address. The default is 64.
+// GenerateRFC7217Addr() only applies the scope to 64bit IIDs since the
+// position of the 'u' bit is undefined otherwise. An EUI-64 IID must be at
+// least 64 bits, it fills the final 64 and any bits above it are set to zero
+func WithIIDLength(n int) Option {
+	return func(o *options) {
+		o.iidLen = n
+	}
+}
+
+// WithStableByDefault follows RFC8064 in using a stable, semantically opaque
+// IID rather than one embedding the hardware address. It causes
+// MakeEUI64Addr() to return the address GenerateRFC7217Addr() would with the
+// supplied secret, a counter of 0, no network ID and SHA256. Should that IID
+// be reserved the counter is incremented, as StableGenerator does, up to
+// IDGenRetries times before giving up and returning nil.
+// MakeEUI64AddrForNet() does the same, returning ErrIDGenRetriesExceeded. It
+// has no effect on the other functions since their IIDs are already opaque
+func WithStableByDefault(secret []byte) Option {
+	return func(o *options) {
+		o.secret = secret
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{iidLen: 64}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// zeroIID clears the final n bits of ip
+func zeroIID(ip net.IP, n int) {
+	for i := len(ip) - 1; n > 0; i-- {
+		if n >= 8 {
+			ip[i] = 0
+		} else {
+			ip[i] &^= byte(1<<uint(n) - 1)
+		}
+		n -= 8
+	}
+}
+
+// isZeroIID returns true if the final n bits of ip are all zero
+func isZeroIID(ip net.IP, n int) bool {
+	xip := make(net.IP, len(ip))
+	copy(xip, ip)
+	zeroIID(xip, n)
+	return xip.Equal(ip)
+}
+
+// fillIID replaces the final n bits of ip with the leftmost n bits of b,
+// which must be at least n bits long
+func fillIID(ip net.IP, b []byte, n int) {
+	zeroIID(ip, n)
+
+	v := new(big.Int).SetBytes(b)
+	v.Rsh(v, uint(len(b)*8-n))
+	a := new(big.Int).SetBytes(ip)
+	a.Or(a, v)
+
+	xb := a.Bytes()
+	for i := range ip {
+		ip[i] = 0
+	}
+	copy(ip[len(ip)-len(xb):], xb)
+}
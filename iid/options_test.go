@@ -0,0 +1,168 @@
+package iid
+
+import (
+	"bytes"
+	"crypto"
+	"net"
+	"testing"
+)
+
+var (
+	optPrefix = net.ParseIP("2001:db8:1111:2222:3333:4444::")
+	optHW, _  = net.ParseMAC("99:88:77:66:55:44")
+	optNetID  = []byte("01234567")
+	optSecret = []byte("secret")
+)
+
+func TestWithRFC7136(t *testing.T) {
+	want := MakeEUI64Addr(optPrefix, optHW, ScopeInvert)
+	for _, scope := range []Scope{ScopeNone, ScopeGlobal, ScopeLocal, ScopeInvert} {
+		out := MakeEUI64Addr(optPrefix, optHW, scope, WithRFC7136())
+		if !out.Equal(want) {
+			t.Errorf("EUI64 scope %d: expected %s got %s", scope, want, out)
+		}
+	}
+
+	// the RFC7217 IID is the hash whatever the scope, with or without the
+	// option, even when WithScopeBit() asks for the scope to be applied
+	want, _ = GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeNone)
+	for _, scope := range []Scope{ScopeNone, ScopeGlobal, ScopeLocal, ScopeInvert} {
+		for _, opts := range [][]Option{nil, {WithRFC7136()}, {WithScopeBit(), WithRFC7136()}} {
+			out, err := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, scope, opts...)
+			if err != nil || !out.Equal(want) {
+				t.Errorf("RFC7217 scope %d with %d options: expected %s got %s, %v", scope, len(opts), want, out, err)
+			}
+		}
+	}
+
+	opaque, _ := MakeOpaqueAddr(optPrefix, optHW, 1, optNetID, optSecret)
+	xopaque, _ := MakeOpaqueAddr(optPrefix, optHW, 1, optNetID, optSecret, WithRFC7136())
+	if !opaque.Equal(want) || !xopaque.Equal(want) {
+		t.Errorf("Opaque: expected %s got %s and %s", want, opaque, xopaque)
+	}
+}
+
+func TestWithScopeBit(t *testing.T) {
+	hash, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeNone)
+	global, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeGlobal, WithScopeBit())
+	local, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeLocal, WithScopeBit())
+	invert, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeInvert, WithScopeBit())
+	if global[8]&0x02 == 0 || local[8]&0x02 != 0 || invert[8] != hash[8]^0x02 {
+		t.Errorf("expected the scope to set the 'u' bit, got %s, %s and %s", global, local, invert)
+	}
+	if !bytes.Equal(global[9:], hash[9:]) || !bytes.Equal(local[9:], hash[9:]) {
+		t.Errorf("expected only the 'u' bit to change, got %s and %s from %s", global, local, hash)
+	}
+
+	opaque, _ := MakeOpaqueAddr(optPrefix, optHW, 1, optNetID, optSecret, WithScopeBit())
+	if !opaque.Equal(global) {
+		t.Errorf("Opaque: expected %s got %s", global, opaque)
+	}
+}
+
+var IIDLengthTests = []struct {
+	name   string
+	length int
+	err    error
+}{
+	{"Shorter", 56, nil},
+	{"Nibble", 60, nil},
+	{"Default", 64, nil},
+	{"Longer", 80, nil},
+	{"All", 128, nil},
+	{"Zero", 0, ErrBadIIDLength},
+	{"TooLong", 129, ErrBadIIDLength},
+}
+
+func TestWithIIDLength_RFC7217(t *testing.T) {
+	// with a 64bit IID and ScopeNone the IID is the leftmost 64bits of the
+	// hash, so every other length can be checked against it
+	iid64, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeNone)
+	for _, tt := range IIDLengthTests {
+		out, err := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeNone, WithIIDLength(tt.length))
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		plen := 128 - tt.length
+		if !samePrefixBits(out, optPrefix, plen) {
+			t.Errorf("%s: prefix not preserved in %s", tt.name, out)
+		}
+		if tt.length%8 == 0 {
+			n := tt.length / 8
+			if n > 8 {
+				n = 8
+			}
+			if !bytes.Equal(out[16-tt.length/8:16-tt.length/8+n], iid64[8:8+n]) {
+				t.Errorf("%s: %s does not start with the leftmost hash bits", tt.name, out)
+			}
+		}
+	}
+
+	out, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, optNetID, optSecret, crypto.SHA256, ScopeNone, WithIIDLength(60))
+	if out[8]&0x0f != iid64[8]>>4 || out[9] != iid64[8]<<4|iid64[9]>>4 {
+		t.Errorf("Nibble: %s is not the leftmost 60 hash bits", out)
+	}
+}
+
+func TestWithIIDLength_EUI64(t *testing.T) {
+	want := MakeEUI64Addr(optPrefix, optHW, ScopeGlobal)
+
+	out := MakeEUI64Addr(optPrefix, optHW, ScopeGlobal, WithIIDLength(72))
+	if !bytes.Equal(out[:7], optPrefix[:7]) || out[7] != 0 || !bytes.Equal(out[8:], want[8:]) {
+		t.Errorf("72bit: expected a zeroed 8th octet and EUI-64 IID, got %s", out)
+	}
+
+	if out := MakeEUI64Addr(optPrefix, optHW, ScopeGlobal, WithIIDLength(56)); out != nil {
+		t.Errorf("56bit: expected <nil> got %s", out)
+	}
+}
+
+func TestWithStableByDefault(t *testing.T) {
+	want, _ := GenerateRFC7217Addr(optPrefix, optHW, 0, []byte{}, optSecret, crypto.SHA256, ScopeGlobal)
+
+	out := MakeEUI64Addr(optPrefix, optHW, ScopeGlobal, WithStableByDefault(optSecret))
+	if !out.Equal(want) {
+		t.Errorf("expected %s got %s", want, out)
+	}
+	if IsEUI64Addr(out) {
+		t.Errorf("stable address %s looks like EUI-64", out)
+	}
+
+	opaque, _ := MakeOpaqueAddr(optPrefix, optHW, 1, optNetID, optSecret)
+	xopaque, _ := MakeOpaqueAddr(optPrefix, optHW, 1, optNetID, optSecret, WithStableByDefault([]byte("other")))
+	if !opaque.Equal(xopaque) {
+		t.Errorf("option changed an opaque address: %s vs %s", opaque, xopaque)
+	}
+}
+
+func TestWithStableByDefault_Collision(t *testing.T) {
+	collide, _ := GenerateRFC7217Addr(optPrefix, optHW, 0, []byte{}, optSecret, crypto.SHA256, ScopeGlobal)
+	want, _ := GenerateRFC7217Addr(optPrefix, optHW, 1, []byte{}, optSecret, crypto.SHA256, ScopeGlobal)
+
+	saved := Registry
+	defer func() { Registry = saved }()
+	Registry = append([]*Reservation{{collide[8:], collide[8:], "Test", "RFC0"}}, saved...)
+
+	if out := MakeEUI64Addr(optPrefix, optHW, ScopeGlobal, WithStableByDefault(optSecret)); !out.Equal(want) {
+		t.Errorf("expected the colliding IID to be skipped for %s, got %s", want, out)
+	}
+
+	Registry = append([]*Reservation{{[]byte{0, 0, 0, 0, 0, 0, 0, 0}, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "Test", "RFC0"}}, saved...)
+	if out := MakeEUI64Addr(optPrefix, optHW, ScopeGlobal, WithStableByDefault(optSecret)); out != nil {
+		t.Errorf("expected <nil> once IDGenRetries is exceeded, got %s", out)
+	}
+}
+
+func samePrefixBits(a, b net.IP, n int) bool {
+	for i := 0; i < n; i++ {
+		if a[i/8]&(0x80>>uint(i%8)) != b[i/8]&(0x80>>uint(i%8)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package iid
+
+import (
+	"crypto"
+	"errors"
+	"net"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	ErrBadHardwareAddr = errors.New("hardware address must be between 48 and 64 bits")
+	ErrPrefixTooLong   = errors.New("prefix leaves no room for the IID")
+)
+
+// MakeEUI64AddrForNet is MakeEUI64Addr() for a prefix of any length up to
+// /64, the hardware address filling the final 64bits of the host portion
+// and any host bits above it set to zero. ErrPrefixTooLong is returned if
+// the prefix is longer than /64, ErrBadHardwareAddr if the hardware address
+// is the wrong size and iplib.ErrNotIP6 if the prefix is not v6. Any
+// WithIIDLength() option is overridden by the prefix length.
+//
+// With WithStableByDefault() the address comes from
+// GenerateRFC7217AddrForNet() instead, so any prefix shorter than /128 will
+// do. As with MakeEUI64Addr() the counter is incremented should the IID
+// collide, and ErrIDGenRetriesExceeded returned after IDGenRetries attempts
+func MakeEUI64AddrForNet(n iplib.Net, hw net.HardwareAddr, scope Scope, opts ...Option) (net.IP, error) {
+	ones, err := prefixLength(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret := newOptions(opts).secret; secret != nil {
+		for counter := int64(0); counter <= IDGenRetries; counter++ {
+			addr, err := GenerateRFC7217AddrForNet(n, hw, counter, []byte{}, secret, crypto.SHA256, scope, opts...)
+			if err == ErrIIDAddressCollision {
+				continue
+			}
+			return addr, err
+		}
+		return nil, ErrIDGenRetriesExceeded
+	}
+	if ones > 64 {
+		return nil, ErrPrefixTooLong
+	}
+	if len(hw) < 6 || len(hw) > 8 {
+		return nil, ErrBadHardwareAddr
+	}
+	return MakeEUI64Addr(n.IP, hw, scope, append(opts, WithIIDLength(128-ones))...), nil
+}
+
+// GenerateRFC7217AddrForNet is GenerateRFC7217Addr() for a prefix of any
+// length, as RFC7217 allows, with the IID filling exactly the host bits of
+// n. ErrPrefixTooLong is returned for a /128 and iplib.ErrNotIP6 if n is not
+// v6. Any WithIIDLength() option is overridden by the prefix length. As with
+// a /64, ErrIIDAddressCollision is returned if the host bits are all zero
+// since that is the Subnet-Router anycast address of n
+func GenerateRFC7217AddrForNet(n iplib.Net, hw net.HardwareAddr, counter int64, netid, secret []byte, htype crypto.Hash, scope Scope, opts ...Option) (net.IP, error) {
+	ones, err := prefixLength(n)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateRFC7217Addr(n.IP, hw, counter, netid, secret, htype, scope, append(opts, WithIIDLength(128-ones))...)
+}
+
+// MakeOpaqueAddrForNet is MakeOpaqueAddr() for a prefix of any length, see
+// GenerateRFC7217AddrForNet()
+func MakeOpaqueAddrForNet(n iplib.Net, hw net.HardwareAddr, counter int64, netid, secret []byte, opts ...Option) (net.IP, error) {
+	return GenerateRFC7217AddrForNet(n, hw, counter, netid, secret, crypto.SHA256, ScopeGlobal, opts...)
+}
+
+// prefixLength returns the prefix length of n, checking that it is v6 and
+// leaves at least one host bit
+func prefixLength(n iplib.Net) (int, error) {
+	if n.Version() != 6 || len(n.IP) != net.IPv6len {
+		return 0, iplib.ErrNotIP6
+	}
+	ones, _ := n.Mask.Size()
+	if ones >= 128 {
+		return 0, ErrPrefixTooLong
+	}
+	return ones, nil
+}
@@ -0,0 +1,132 @@
+package iid
+
+import (
+	"crypto"
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var ForNetTests = []struct {
+	name    string
+	cidr    string
+	counter int64
+	out     string
+	err     error
+}{
+	{"Prefix48", "2001:db8:1111::/48", 1, "2001:db8:1111:c6fa:ba02:41ab:282c:27be", nil},
+	{"Prefix64", "2001:db8:1111:2222::/64", 1, "2001:db8:1111:2222:c6fa:ba02:41ab:282c", nil},
+	{"Prefix80", "2001:db8:1111:2222:3333::/80", 1, "2001:db8:1111:2222:3333:c6fa:ba02:41ab", nil},
+	{"Prefix96", "2001:db8:1111:2222:3333:4444::/96", 1, "2001:db8:1111:2222:3333:4444:c6fa:ba02", nil},
+	{"Prefix127", "2001:db8::/127", 1, "2001:db8::1", nil},
+	{"Prefix127Anycast", "2001:db8:1:2:3:4:5:6/127", 0, "", ErrIIDAddressCollision},
+	{"Prefix128", "2001:db8::1/128", 1, "", ErrPrefixTooLong},
+	{"NotIP6", "192.168.0.0/16", 1, "", iplib.ErrNotIP6},
+}
+
+func TestMakeOpaqueAddrForNet(t *testing.T) {
+	hw, _ := net.ParseMAC("77:88:99:aa:bb:cc")
+	for _, tt := range ForNetTests {
+		_, n, _ := iplib.ParseCIDR(tt.cidr)
+		out, err := MakeOpaqueAddrForNet(n, hw, tt.counter, []byte("01234567"), []byte("secret"))
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if out.String() != tt.out {
+			t.Errorf("%s: expected %s got %s", tt.name, tt.out, out)
+		}
+		if !n.Contains(out) {
+			t.Errorf("%s: %s is not in %s", tt.name, out, tt.cidr)
+		}
+	}
+}
+
+func TestGenerateRFC7217AddrForNet(t *testing.T) {
+	hw, _ := net.ParseMAC("77:88:99:aa:bb:cc")
+	_, n, _ := iplib.ParseCIDR("2001:db8:1111:2222:3333::/80")
+
+	want, _ := GenerateRFC7217Addr(n.IP, hw, 1, []byte{}, []byte("secret"), crypto.SHA384, ScopeNone, WithIIDLength(48))
+	out, err := GenerateRFC7217AddrForNet(n, hw, 1, []byte{}, []byte("secret"), crypto.SHA384, ScopeNone, WithIIDLength(16))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !out.Equal(want) {
+		t.Errorf("expected the prefix length to override WithIIDLength, want %s got %s", want, out)
+	}
+}
+
+var EUI64ForNetTests = []struct {
+	name string
+	cidr string
+	hw   string
+	out  string
+	err  error
+}{
+	{"Prefix48", "2001:db8:1111::/48", "99:88:77:66:55:44", "2001:db8:1111:0:9b88:77ff:fe66:5544", nil},
+	{"Prefix56", "2001:db8:1111:22ff::/56", "99:88:77:66:55:44", "2001:db8:1111:2200:9b88:77ff:fe66:5544", nil},
+	{"Prefix64", "2001:db8:1111:2222::/64", "99:88:77:66:55:44", "2001:db8:1111:2222:9b88:77ff:fe66:5544", nil},
+	{"Prefix80", "2001:db8:1111:2222:3333::/80", "99:88:77:66:55:44", "", ErrPrefixTooLong},
+	{"BadHardwareAddr", "2001:db8:1111:2222::/64", "99:88:77:66", "", ErrBadHardwareAddr},
+	{"NotIP6", "192.168.0.0/16", "99:88:77:66:55:44", "", iplib.ErrNotIP6},
+}
+
+func TestMakeEUI64AddrForNet(t *testing.T) {
+	for _, tt := range EUI64ForNetTests {
+		_, n, _ := iplib.ParseCIDR(tt.cidr)
+		hw, _ := net.ParseMAC(tt.hw)
+		if hw == nil {
+			hw = net.HardwareAddr{0x99, 0x88, 0x77, 0x66}
+		}
+		out, err := MakeEUI64AddrForNet(n, hw, ScopeGlobal)
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err == nil && out.String() != tt.out {
+			t.Errorf("%s: expected %s got %s", tt.name, tt.out, out)
+		}
+	}
+}
+
+func TestMakeEUI64AddrForNet_Stable(t *testing.T) {
+	hw, _ := net.ParseMAC("99:88:77:66:55:44")
+	_, n, _ := iplib.ParseCIDR("2001:db8:1111:2222:3333:4444::/96")
+
+	want, _ := GenerateRFC7217AddrForNet(n, hw, 0, []byte{}, []byte("secret"), crypto.SHA256, ScopeGlobal)
+	out, err := MakeEUI64AddrForNet(n, hw, ScopeGlobal, WithStableByDefault([]byte("secret")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !out.Equal(want) || !n.Contains(out) {
+		t.Errorf("expected %s in %s got %s", want, n.IPNet.String(), out)
+	}
+}
+
+func TestMakeEUI64AddrForNet_StableCollision(t *testing.T) {
+	hw, _ := net.ParseMAC("99:88:77:66:55:44")
+	secret := []byte("secret")
+
+	// counter 0 hashes to zero host bits in a /127, the Subnet-Router anycast
+	// address, so counter 1 must be used
+	_, n, _ := iplib.ParseCIDR("2001:db8:1:2:3:4:5:6/127")
+	if _, err := GenerateRFC7217AddrForNet(n, hw, 0, []byte{}, secret, crypto.SHA256, ScopeGlobal); err != ErrIIDAddressCollision {
+		t.Fatalf("expected counter 0 to collide got '%v'", err)
+	}
+	want, _ := GenerateRFC7217AddrForNet(n, hw, 1, []byte{}, secret, crypto.SHA256, ScopeGlobal)
+	out, err := MakeEUI64AddrForNet(n, hw, ScopeGlobal, WithStableByDefault(secret))
+	if err != nil || !out.Equal(want) {
+		t.Errorf("expected %s got %s, %v", want, out, err)
+	}
+
+	saved := Registry
+	defer func() { Registry = saved }()
+	Registry = append([]*Reservation{{[]byte{0, 0, 0, 0, 0, 0, 0, 0}, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "Test", "RFC0"}}, saved...)
+	if _, err := MakeEUI64AddrForNet(n, hw, ScopeGlobal, WithStableByDefault(secret)); err != ErrIDGenRetriesExceeded {
+		t.Errorf("expected ErrIDGenRetriesExceeded got '%v'", err)
+	}
+}
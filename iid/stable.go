@@ -0,0 +1,238 @@
+package iid
+
+import (
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kenits/iplib"
+)
+
+// IDGenRetries is IDGEN_RETRIES from RFC7217, the number of times the
+// DAD_Counter may be incremented before giving up on an address
+const IDGenRetries = 3
+
+var (
+	ErrIDGenRetriesExceeded = errors.New("exceeded IDGEN_RETRIES generating a stable IID")
+	ErrNoCounterStore       = errors.New("StableGenerator has no CounterStore, use NewStableGenerator()")
+)
+
+// CounterStore persists the RFC7217 DAD_Counter for each combination of
+// prefix, interface and network ID so that StableGenerator produces the same
+// address every time. Keys are opaque strings; a key that has never been set
+// must return a counter of zero and no error
+type CounterStore interface {
+	GetCounter(key string) (int64, error)
+	SetCounter(key string, counter int64) error
+}
+
+// FileCounterStore is a CounterStore that keeps its counters in a JSON file.
+// The file is read on every call and replaced atomically on every update, so
+// it is safe for concurrent use within a process and survives restarts
+type FileCounterStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// MemoryCounterStore is a CounterStore that keeps its counters in memory. It
+// is safe for concurrent use but the counters are lost when the process exits
+type MemoryCounterStore struct {
+	counters map[string]int64
+	mu       sync.Mutex
+}
+
+// StableGenerator produces RFC7217 stable, semantically opaque addresses.
+// Unlike calling GenerateRFC7217Addr() directly it owns the secret and keeps
+// the DAD_Counter in a CounterStore, retrying with the next counter when an
+// IID collides with the reserved list or DAD fails, up to Retries times. It
+// must be created with NewStableGenerator(), the zero value has no secret or
+// CounterStore and its methods return ErrNoCounterStore
+type StableGenerator struct {
+	// Hash is the hash function, SHA256 by default
+	Hash crypto.Hash
+
+	// Scope is the scope of generated IIDs, ScopeGlobal by default
+	Scope Scope
+
+	// Retries is the most the counter may be incremented, IDGenRetries by
+	// default
+	Retries int64
+
+	secret []byte
+	store  CounterStore
+}
+
+// NewMemoryCounterStore returns an empty MemoryCounterStore
+func NewMemoryCounterStore() *MemoryCounterStore {
+	return &MemoryCounterStore{counters: make(map[string]int64)}
+}
+
+// GetCounter returns the counter stored under key, or zero if there is none
+func (s *MemoryCounterStore) GetCounter(key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counters[key], nil
+}
+
+// SetCounter stores counter under key
+func (s *MemoryCounterStore) SetCounter(key string, counter int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[key] = counter
+	return nil
+}
+
+// NewFileCounterStore returns a FileCounterStore using the named file, which
+// need not exist yet
+func NewFileCounterStore(path string) *FileCounterStore {
+	return &FileCounterStore{path: path}
+}
+
+// GetCounter returns the counter stored under key, or zero if there is none
+func (s *FileCounterStore) GetCounter(key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	return counters[key], nil
+}
+
+// SetCounter stores counter under key
+func (s *FileCounterStore) SetCounter(key string, counter int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, err := s.read()
+	if err != nil {
+		return err
+	}
+	counters[key] = counter
+
+	b, err := json.MarshalIndent(counters, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *FileCounterStore) read() (map[string]int64, error) {
+	counters := make(map[string]int64)
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return counters, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &counters); err != nil {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// NewStableGenerator returns a StableGenerator using the supplied secret and
+// CounterStore, with the same SHA256 hash and global scope as
+// MakeOpaqueAddr(). If store is nil a new MemoryCounterStore is used, so
+// addresses that needed the counter incremented will change on restart
+func NewStableGenerator(secret []byte, store CounterStore) *StableGenerator {
+	if store == nil {
+		store = NewMemoryCounterStore()
+	}
+	g := &StableGenerator{
+		Hash:    crypto.SHA256,
+		Scope:   ScopeGlobal,
+		Retries: IDGenRetries,
+		secret:  make([]byte, len(secret)),
+		store:   store,
+	}
+	copy(g.secret, secret)
+	return g
+}
+
+// Addr returns the stable address for the supplied prefix, hardware address
+// and network ID, see GenerateRFC7217Addr() for a description of each. If the
+// IID collides with the reserved list the counter is incremented and stored
+// and the next one tried. ErrIDGenRetriesExceeded is returned once the counter
+// would pass Retries
+func (g *StableGenerator) Addr(ip net.IP, hw net.HardwareAddr, netid []byte) (net.IP, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return nil, iplib.ErrNotIP6
+	}
+	if g.store == nil {
+		return nil, ErrNoCounterStore
+	}
+
+	key := counterKey(ip, hw, netid)
+	counter, err := g.store.GetCounter(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for ; counter <= g.Retries; counter++ {
+		xhw := make(net.HardwareAddr, len(hw))
+		copy(xhw, hw)
+
+		addr, err := GenerateRFC7217Addr(ip, xhw, counter, netid, g.secret, g.Hash, g.Scope)
+		if err == ErrIIDAddressCollision {
+			if err := g.store.SetCounter(key, counter+1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return addr, err
+	}
+	return nil, ErrIDGenRetriesExceeded
+}
+
+// DADFailed is called when duplicate address detection fails for the
+// address last returned by Addr() for the same inputs. The counter is
+// incremented and stored and the replacement address returned
+func (g *StableGenerator) DADFailed(ip net.IP, hw net.HardwareAddr, netid []byte) (net.IP, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return nil, iplib.ErrNotIP6
+	}
+	if g.store == nil {
+		return nil, ErrNoCounterStore
+	}
+
+	key := counterKey(ip, hw, netid)
+	counter, err := g.store.GetCounter(key)
+	if err != nil {
+		return nil, err
+	}
+	if counter >= g.Retries {
+		return nil, ErrIDGenRetriesExceeded
+	}
+	if err := g.store.SetCounter(key, counter+1); err != nil {
+		return nil, err
+	}
+	return g.Addr(ip, hw, netid)
+}
+
+// counterKey identifies a DAD_Counter by the /64 prefix, hardware address
+// and network ID it applies to
+func counterKey(ip net.IP, hw net.HardwareAddr, netid []byte) string {
+	return hex.EncodeToString(ip[:8]) + "-" + hex.EncodeToString(hw) + "-" + hex.EncodeToString(netid)
+}
@@ -0,0 +1,194 @@
+package iid
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	stablePrefix = net.ParseIP("2001:db8::")
+	stableHW, _  = net.ParseMAC("77:88:99:aa:bb:cc")
+	stableNetID  = []byte("01234567")
+	stableSecret = []byte("secret")
+)
+
+type mapCounterStore map[string]int64
+
+func (m mapCounterStore) GetCounter(key string) (int64, error) {
+	return m[key], nil
+}
+
+func (m mapCounterStore) SetCounter(key string, counter int64) error {
+	m[key] = counter
+	return nil
+}
+
+func tempCounterFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "iid")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	return filepath.Join(dir, "counters.json"), func() { os.RemoveAll(dir) }
+}
+
+func TestFileCounterStore(t *testing.T) {
+	path, cleanup := tempCounterFile(t)
+	defer cleanup()
+
+	s := NewFileCounterStore(path)
+	if c, err := s.GetCounter("a"); err != nil || c != 0 {
+		t.Fatalf("missing file: expected 0, <nil> got %d, %v", c, err)
+	}
+	if err := s.SetCounter("a", 2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := s.SetCounter("b", 1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	xs := NewFileCounterStore(path)
+	if c, _ := xs.GetCounter("a"); c != 2 {
+		t.Errorf("expected a=2 after reopening got %d", c)
+	}
+	if c, _ := xs.GetCounter("b"); c != 1 {
+		t.Errorf("expected b=1 after reopening got %d", c)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := xs.GetCounter("a"); err == nil {
+		t.Errorf("expected an error reading a corrupt file")
+	}
+}
+
+func TestStableGenerator_Addr(t *testing.T) {
+	path, cleanup := tempCounterFile(t)
+	defer cleanup()
+
+	g := NewStableGenerator(stableSecret, NewFileCounterStore(path))
+	want, _ := MakeOpaqueAddr(stablePrefix, stableHW, 0, stableNetID, stableSecret)
+
+	for i := 0; i < 2; i++ {
+		addr, err := g.Addr(stablePrefix, stableHW, stableNetID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !addr.Equal(want) {
+			t.Errorf("call %d: expected %s got %s", i, want, addr)
+		}
+	}
+
+	// a second generator sharing the file gets the same address
+	xg := NewStableGenerator(stableSecret, NewFileCounterStore(path))
+	if addr, _ := xg.Addr(stablePrefix, stableHW, stableNetID); !addr.Equal(want) {
+		t.Errorf("expected %s from a new generator got %s", want, addr)
+	}
+
+	if _, err := g.Addr(net.ParseIP("192.168.1.1"), stableHW, stableNetID); err != iplib.ErrNotIP6 {
+		t.Errorf("expected ErrNotIP6 got '%v'", err)
+	}
+}
+
+func TestStableGenerator_DADFailed(t *testing.T) {
+	store := mapCounterStore{}
+	g := NewStableGenerator(stableSecret, store)
+
+	first, err := g.Addr(stablePrefix, stableHW, stableNetID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	next, err := g.DADFailed(stablePrefix, stableHW, stableNetID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if next.String() != "2001:db8::c6fa:ba02:41ab:282c" {
+		t.Errorf("expected the counter 1 address got %s", next)
+	}
+	if next.Equal(first) {
+		t.Errorf("DAD failure did not change the address")
+	}
+	if addr, _ := g.Addr(stablePrefix, stableHW, stableNetID); !addr.Equal(next) {
+		t.Errorf("expected Addr() to return %s after DAD failure got %s", next, addr)
+	}
+
+	for i := 2; i <= IDGenRetries; i++ {
+		if _, err := g.DADFailed(stablePrefix, stableHW, stableNetID); err != nil {
+			t.Fatalf("retry %d: unexpected error: %s", i, err)
+		}
+	}
+	if _, err := g.DADFailed(stablePrefix, stableHW, stableNetID); err != ErrIDGenRetriesExceeded {
+		t.Errorf("expected ErrIDGenRetriesExceeded got '%v'", err)
+	}
+}
+
+func TestStableGenerator_ReservedCollision(t *testing.T) {
+	collide, _ := MakeOpaqueAddr(stablePrefix, stableHW, 0, stableNetID, stableSecret)
+	want, _ := MakeOpaqueAddr(stablePrefix, stableHW, 1, stableNetID, stableSecret)
+
+	saved := Registry
+	defer func() { Registry = saved }()
+	Registry = append([]*Reservation{{collide[8:], collide[8:], "Test", "RFC0"}}, saved...)
+
+	store := mapCounterStore{}
+	g := NewStableGenerator(stableSecret, store)
+	addr, err := g.Addr(stablePrefix, stableHW, stableNetID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !addr.Equal(want) {
+		t.Errorf("expected the colliding IID to be skipped, got %s", addr)
+	}
+	if c, _ := store.GetCounter(counterKey(stablePrefix, stableHW, stableNetID)); c != 1 {
+		t.Errorf("expected the counter to be stored as 1 got %d", c)
+	}
+
+	g.Retries = 0
+	store[counterKey(stablePrefix, stableHW, stableNetID)] = 0
+	if _, err := g.Addr(stablePrefix, stableHW, stableNetID); err != ErrIDGenRetriesExceeded {
+		t.Errorf("expected ErrIDGenRetriesExceeded got '%v'", err)
+	}
+}
+
+func TestStableGenerator_NilStore(t *testing.T) {
+	g := NewStableGenerator(stableSecret, nil)
+	a, err := g.Addr(stablePrefix, stableHW, stableNetID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := g.DADFailed(stablePrefix, stableHW, stableNetID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a.Equal(b) {
+		t.Errorf("expected DADFailed to change the address, got %s twice", a)
+	}
+	if c, _ := g.Addr(stablePrefix, stableHW, stableNetID); !c.Equal(b) {
+		t.Errorf("expected the in-memory counter to be kept, got %s want %s", c, b)
+	}
+
+	var zero StableGenerator
+	if _, err := zero.Addr(stablePrefix, stableHW, stableNetID); err != ErrNoCounterStore {
+		t.Errorf("zero value Addr: expected ErrNoCounterStore got %v", err)
+	}
+	if _, err := zero.DADFailed(stablePrefix, stableHW, stableNetID); err != ErrNoCounterStore {
+		t.Errorf("zero value DADFailed: expected ErrNoCounterStore got %v", err)
+	}
+}
+
+func TestMemoryCounterStore(t *testing.T) {
+	s := NewMemoryCounterStore()
+	if c, err := s.GetCounter("a"); err != nil || c != 0 {
+		t.Fatalf("expected 0, <nil> got %d, %v", c, err)
+	}
+	s.SetCounter("a", 2)
+	if c, _ := s.GetCounter("a"); c != 2 {
+		t.Errorf("expected a=2 got %d", c)
+	}
+}
@@ -0,0 +1,239 @@
+package iid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+// Default values for TempAddrConfig from RFC8981 section 3.8
+const (
+	// TempValidLifetime is the longest a temporary address may remain valid
+	TempValidLifetime = 2 * 24 * time.Hour
+
+	// TempPreferredLifetime is the longest a temporary address may remain
+	// preferred
+	TempPreferredLifetime = 24 * time.Hour
+
+	// TempIDGenRetries is the number of times to try generating an IID that
+	// does not collide with a reserved IID, or with a previous IID, before
+	// giving up
+	TempIDGenRetries = 3
+
+	// RegenAdvance is how long before a temporary address is deprecated that
+	// a new one should be generated. It is 2 + (TEMP_IDGEN_RETRIES *
+	// DupAddrDetectTransmits * RetransTimer / 1000) seconds, using the
+	// RFC4862 defaults of one transmit and a one second timer
+	RegenAdvance = (2 + TempIDGenRetries) * time.Second
+)
+
+var (
+	ErrTempLifetimeTooShort = errors.New("preferred lifetime is too short for a temporary address")
+)
+
+// TempAddrConfig holds the parameters used to generate temporary addresses
+// as described in RFC4941 and its successor RFC8981. The zero value is not
+// useful, start from DefaultTempAddrConfig()
+type TempAddrConfig struct {
+	// ValidLifetime is TEMP_VALID_LIFETIME, the upper bound on how long a
+	// temporary address is valid
+	ValidLifetime time.Duration
+
+	// PreferredLifetime is TEMP_PREFERRED_LIFETIME, the upper bound on how
+	// long a temporary address is preferred
+	PreferredLifetime time.Duration
+
+	// MaxDesyncFactor is MAX_DESYNC_FACTOR, the upper bound on the random
+	// amount each address' preferred lifetime is shortened by so that hosts
+	// do not all regenerate at once
+	MaxDesyncFactor time.Duration
+
+	// RegenAdvance is REGEN_ADVANCE, how long before deprecation a new
+	// address should be generated
+	RegenAdvance time.Duration
+
+	// Retries is TEMP_IDGEN_RETRIES
+	Retries int
+
+	// Rand is the source of randomness for IIDs and desync factors. If nil
+	// crypto/rand is used
+	Rand io.Reader
+}
+
+// TempAddr is a temporary address along with the times that govern its
+// life-cycle
+type TempAddr struct {
+	// IP is the temporary address
+	IP net.IP
+
+	// Created is when the address was generated
+	Created time.Time
+
+	// DesyncFactor is the random amount the preferred lifetime was shortened
+	// by
+	DesyncFactor time.Duration
+
+	// PreferredLifetime and ValidLifetime are measured from Created
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+
+	// RegenAdvance is copied from the TempAddrConfig used to create the
+	// address
+	RegenAdvance time.Duration
+}
+
+// DefaultTempAddrConfig returns a TempAddrConfig holding the defaults from
+// RFC8981, with MaxDesyncFactor set to 0.4 * TempPreferredLifetime
+func DefaultTempAddrConfig() TempAddrConfig {
+	return TempAddrConfig{
+		ValidLifetime:     TempValidLifetime,
+		PreferredLifetime: TempPreferredLifetime,
+		MaxDesyncFactor:   TempPreferredLifetime * 2 / 5,
+		RegenAdvance:      RegenAdvance,
+		Retries:           TempIDGenRetries,
+	}
+}
+
+// GenerateTempAddr returns a temporary address in the /64 of the supplied
+// v6 IP, created at now. prefixPreferred and prefixValid are the lifetimes
+// advertised for the prefix, the address' lifetimes will not exceed them.
+// If the resulting preferred lifetime is no longer than RegenAdvance the
+// address would be deprecated before it could be used and
+// ErrTempLifetimeTooShort is returned
+func (c TempAddrConfig) GenerateTempAddr(ip net.IP, prefixPreferred, prefixValid time.Duration, now time.Time) (TempAddr, error) {
+	return c.generate(ip, prefixPreferred, prefixValid, now, nil)
+}
+
+// RegenerateTempAddr returns a replacement for old, generated at now in the
+// same /64 with a new IID and desync factor. The new IID is guaranteed to
+// differ from the old one
+func (c TempAddrConfig) RegenerateTempAddr(old TempAddr, prefixPreferred, prefixValid time.Duration, now time.Time) (TempAddr, error) {
+	return c.generate(old.IP, prefixPreferred, prefixValid, now, old.IP)
+}
+
+func (c TempAddrConfig) generate(ip net.IP, prefixPreferred, prefixValid time.Duration, now time.Time, old net.IP) (TempAddr, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return TempAddr{}, iplib.ErrNotIP6
+	}
+
+	desync, err := c.desyncFactor()
+	if err != nil {
+		return TempAddr{}, err
+	}
+
+	t := TempAddr{
+		Created:           now,
+		DesyncFactor:      desync,
+		PreferredLifetime: minDuration(c.PreferredLifetime-desync, prefixPreferred),
+		ValidLifetime:     minDuration(c.ValidLifetime, prefixValid),
+		RegenAdvance:      c.RegenAdvance,
+	}
+	if t.PreferredLifetime <= c.RegenAdvance {
+		return TempAddr{}, ErrTempLifetimeTooShort
+	}
+
+	for i := 0; i <= c.Retries; i++ {
+		t.IP, err = GenerateTempIID(ip, c.Rand)
+		if err == ErrIIDAddressCollision || (err == nil && old != nil && t.IP.Equal(old)) {
+			continue
+		}
+		if err != nil {
+			return TempAddr{}, err
+		}
+		return t, nil
+	}
+	return TempAddr{}, ErrIIDAddressCollision
+}
+
+func (c TempAddrConfig) desyncFactor() (time.Duration, error) {
+	if c.MaxDesyncFactor <= 0 {
+		return 0, nil
+	}
+	n, err := randUint64(c.Rand)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n % uint64(c.MaxDesyncFactor+1)), nil
+}
+
+// GenerateTempIID returns the supplied v6 IP with its final 64bits replaced
+// by random ones read from r, or from crypto/rand if r is nil. As RFC8981
+// requires, if the IID collides with the IANA reserved IID list an
+// ErrIIDAddressCollision is returned and the caller should try again. Unlike
+// RFC4941 no bits of the IID are fixed, so ScopeNone is implied
+func GenerateTempIID(ip net.IP, r io.Reader) (net.IP, error) {
+	if len(ip) != net.IPv6len || iplib.EffectiveVersion(ip) != 6 {
+		return nil, iplib.ErrNotIP6
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+
+	tip := make(net.IP, 16)
+	copy(tip, ip[:8])
+	if _, err := io.ReadFull(r, tip[8:]); err != nil {
+		return nil, err
+	}
+
+	if res := GetReservationsForIP(tip); len(res) > 0 {
+		return nil, ErrIIDAddressCollision
+	}
+	return tip, nil
+}
+
+// PreferredUntil returns the time at which the address becomes deprecated
+func (t TempAddr) PreferredUntil() time.Time {
+	return t.Created.Add(t.PreferredLifetime)
+}
+
+// ValidUntil returns the time at which the address becomes invalid and must
+// be removed
+func (t TempAddr) ValidUntil() time.Time {
+	return t.Created.Add(t.ValidLifetime)
+}
+
+// RegenerateAt returns the time at which a replacement address should be
+// generated, RegenAdvance before the address is deprecated
+func (t TempAddr) RegenerateAt() time.Time {
+	return t.PreferredUntil().Add(-t.RegenAdvance)
+}
+
+// IsDeprecated returns true if the address should no longer be used for new
+// communication at the given time
+func (t TempAddr) IsDeprecated(now time.Time) bool {
+	return !now.Before(t.PreferredUntil())
+}
+
+// IsExpired returns true if the address is no longer valid at the given time
+func (t TempAddr) IsExpired(now time.Time) bool {
+	return !now.Before(t.ValidUntil())
+}
+
+// NeedsRegeneration returns true if a replacement address should have been
+// generated by the given time
+func (t TempAddr) NeedsRegeneration(now time.Time) bool {
+	return !now.Before(t.RegenerateAt())
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func randUint64(r io.Reader) (uint64, error) {
+	if r == nil {
+		r = rand.Reader
+	}
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
@@ -0,0 +1,237 @@
+package iid
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	tempPrefix = net.ParseIP("2001:db8:1:2::")
+	tempNow    = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// tempRand returns a reader yielding an 8-byte desync value followed by the
+// supplied IIDs
+func tempRand(desync uint64, iids ...[]byte) *bytes.Reader {
+	b := []byte{
+		byte(desync >> 56), byte(desync >> 48), byte(desync >> 40), byte(desync >> 32),
+		byte(desync >> 24), byte(desync >> 16), byte(desync >> 8), byte(desync),
+	}
+	for _, iid := range iids {
+		b = append(b, iid...)
+	}
+	return bytes.NewReader(b)
+}
+
+var TempAddrTests = []struct {
+	name      string
+	iids      [][]byte
+	preferred time.Duration
+	valid     time.Duration
+	out       string
+	outPref   time.Duration
+	outValid  time.Duration
+	err       error
+}{
+	{
+		"Defaults",
+		[][]byte{{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}},
+		7 * 24 * time.Hour,
+		30 * 24 * time.Hour,
+		"2001:db8:1:2:1122:3344:5566:7788",
+		TempPreferredLifetime - time.Hour,
+		TempValidLifetime,
+		nil,
+	},
+	{
+		"PrefixLifetimes",
+		[][]byte{{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}},
+		2 * time.Hour,
+		4 * time.Hour,
+		"2001:db8:1:2:1122:3344:5566:7788",
+		2 * time.Hour,
+		4 * time.Hour,
+		nil,
+	},
+	{
+		"RetryReserved",
+		[][]byte{
+			{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			{0x02, 0x00, 0x5e, 0xff, 0xfe, 0x00, 0x52, 0x13},
+			{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88},
+		},
+		7 * 24 * time.Hour,
+		30 * 24 * time.Hour,
+		"2001:db8:1:2:1122:3344:5566:7788",
+		TempPreferredLifetime - time.Hour,
+		TempValidLifetime,
+		nil,
+	},
+	{
+		"RetriesExhausted",
+		[][]byte{
+			{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88},
+		},
+		7 * 24 * time.Hour,
+		30 * 24 * time.Hour,
+		"",
+		0,
+		0,
+		ErrIIDAddressCollision,
+	},
+	{
+		"TooShort",
+		[][]byte{{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}},
+		RegenAdvance,
+		4 * time.Hour,
+		"",
+		0,
+		0,
+		ErrTempLifetimeTooShort,
+	},
+}
+
+func TestTempAddrConfig_GenerateTempAddr(t *testing.T) {
+	for _, tt := range TempAddrTests {
+		c := DefaultTempAddrConfig()
+		c.Rand = tempRand(uint64(time.Hour), tt.iids...)
+
+		ta, err := c.GenerateTempAddr(tempPrefix, tt.preferred, tt.valid, tempNow)
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if ta.IP.String() != tt.out {
+			t.Errorf("%s: expected %s got %s", tt.name, tt.out, ta.IP)
+		}
+		if ta.PreferredLifetime != tt.outPref {
+			t.Errorf("%s: expected preferred lifetime %s got %s", tt.name, tt.outPref, ta.PreferredLifetime)
+		}
+		if ta.ValidLifetime != tt.outValid {
+			t.Errorf("%s: expected valid lifetime %s got %s", tt.name, tt.outValid, ta.ValidLifetime)
+		}
+	}
+}
+
+func TestTempAddrConfig_GenerateTempAddrNotIP6(t *testing.T) {
+	c := DefaultTempAddrConfig()
+	_, err := c.GenerateTempAddr(net.ParseIP("192.168.1.1"), time.Hour, time.Hour, tempNow)
+	if err != iplib.ErrNotIP6 {
+		t.Errorf("expected ErrNotIP6 got '%v'", err)
+	}
+}
+
+func TestTempAddrConfig_DesyncFactor(t *testing.T) {
+	c := DefaultTempAddrConfig()
+	for i := 0; i < 100; i++ {
+		ta, err := c.GenerateTempAddr(tempPrefix, 7*24*time.Hour, 30*24*time.Hour, tempNow)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if ta.DesyncFactor < 0 || ta.DesyncFactor > c.MaxDesyncFactor {
+			t.Fatalf("desync factor %s outside of [0, %s]", ta.DesyncFactor, c.MaxDesyncFactor)
+		}
+		if ta.PreferredLifetime != c.PreferredLifetime-ta.DesyncFactor {
+			t.Fatalf("preferred lifetime %s not shortened by desync factor %s", ta.PreferredLifetime, ta.DesyncFactor)
+		}
+		if !bytes.Equal(ta.IP[:8], tempPrefix[:8]) {
+			t.Fatalf("address %s is not in prefix %s", ta.IP, tempPrefix)
+		}
+	}
+}
+
+func TestTempAddrConfig_RegenerateTempAddr(t *testing.T) {
+	iid := []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}
+	next := []byte{0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00}
+
+	c := DefaultTempAddrConfig()
+	c.Rand = tempRand(0, iid)
+	old, err := c.GenerateTempAddr(tempPrefix, 7*24*time.Hour, 30*24*time.Hour, tempNow)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	later := old.RegenerateAt()
+	c.Rand = tempRand(0, iid, next)
+	ta, err := c.RegenerateTempAddr(old, 7*24*time.Hour, 30*24*time.Hour, later)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ta.IP.String() != "2001:db8:1:2:99aa:bbcc:ddee:ff00" {
+		t.Errorf("expected the repeated IID to be skipped, got %s", ta.IP)
+	}
+	if !ta.Created.Equal(later) {
+		t.Errorf("expected creation time %s got %s", later, ta.Created)
+	}
+}
+
+func TestTempAddr_Lifecycle(t *testing.T) {
+	ta := TempAddr{
+		IP:                net.ParseIP("2001:db8:1:2:1122:3344:5566:7788"),
+		Created:           tempNow,
+		PreferredLifetime: 20 * time.Hour,
+		ValidLifetime:     48 * time.Hour,
+		RegenAdvance:      RegenAdvance,
+	}
+
+	if want := tempNow.Add(20 * time.Hour); !ta.PreferredUntil().Equal(want) {
+		t.Errorf("PreferredUntil: expected %s got %s", want, ta.PreferredUntil())
+	}
+	if want := tempNow.Add(48 * time.Hour); !ta.ValidUntil().Equal(want) {
+		t.Errorf("ValidUntil: expected %s got %s", want, ta.ValidUntil())
+	}
+	if want := tempNow.Add(20*time.Hour - 5*time.Second); !ta.RegenerateAt().Equal(want) {
+		t.Errorf("RegenerateAt: expected %s got %s", want, ta.RegenerateAt())
+	}
+
+	var states = []struct {
+		at         time.Duration
+		regenerate bool
+		deprecated bool
+		expired    bool
+	}{
+		{0, false, false, false},
+		{20*time.Hour - 6*time.Second, false, false, false},
+		{20*time.Hour - 5*time.Second, true, false, false},
+		{20 * time.Hour, true, true, false},
+		{48 * time.Hour, true, true, true},
+	}
+	for _, s := range states {
+		now := tempNow.Add(s.at)
+		if ta.NeedsRegeneration(now) != s.regenerate {
+			t.Errorf("%s: expected NeedsRegeneration %t", s.at, s.regenerate)
+		}
+		if ta.IsDeprecated(now) != s.deprecated {
+			t.Errorf("%s: expected IsDeprecated %t", s.at, s.deprecated)
+		}
+		if ta.IsExpired(now) != s.expired {
+			t.Errorf("%s: expected IsExpired %t", s.at, s.expired)
+		}
+	}
+}
+
+func TestGenerateTempIID(t *testing.T) {
+	ip, err := GenerateTempIID(tempPrefix, nil)
+	if err != nil && err != ErrIIDAddressCollision {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err == nil && !bytes.Equal(ip[:8], tempPrefix[:8]) {
+		t.Errorf("address %s is not in prefix %s", ip, tempPrefix)
+	}
+
+	_, err = GenerateTempIID(tempPrefix, bytes.NewReader([]byte{0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x90}))
+	if err != ErrIIDAddressCollision {
+		t.Errorf("expected ErrIIDAddressCollision got '%v'", err)
+	}
+}
@@ -60,7 +60,13 @@ var (
 	ErrBadMaskLength       = errors.New("illegal mask length provided")
 	ErrBroadcastAddress    = errors.New("address is the broadcast address of this netblock (and not considered usable)")
 	ErrNetworkAddress      = errors.New("address is the network address of this netblock (and not considered usable)")
+	ErrNoAddressAvailable  = errors.New("every usable address in this netblock is excluded")
+	ErrNoEmbeddedIP4       = errors.New("address does not contain an embedded v4 address")
+	ErrNoHostnameFunc      = errors.New("no HostnameFunc was supplied")
 	ErrNoValidRange        = errors.New("no netblock can be found between the supplied values")
+	ErrNonZeroUOctet       = errors.New("RFC6052 prefix has a non-zero u-octet")
+	ErrNotIP4              = errors.New("address is not a v4 address")
+	ErrNotIP6              = errors.New("address is not a v6 address")
 )
 
 // ByIP implements sort.Interface for net.IP addresses
@@ -265,7 +271,9 @@ func ExpandIP6(ip net.IP) string {
 }
 
 // ForceIP4 takes a net.IP containing an RFC4291 IPv4-mapped IPv6 address and
-// returns only the encapsulated v4 address.
+// returns only the encapsulated v4 address. It does not check that the input
+// actually is IPv4-mapped and will return the last 4 bytes of any v6 address,
+// see IP6ToIP4() for a stricter alternative.
 func ForceIP4(ip net.IP) net.IP {
 	if len(ip) == 16 {
 		return ip[12:]
@@ -328,6 +336,43 @@ func IncrementIP6By(ip net.IP, count *big.Int) net.IP {
 	return BigintToIP6(z)
 }
 
+// IP4ToIP6Compatible returns the given v4 address as an RFC4291 "IPv4-
+// compatible IPv6 address" (::a.b.c.d). This format is deprecated and should
+// only be used for interoperating with software that still expects it
+func IP4ToIP6Compatible(ip net.IP) (net.IP, error) {
+	return ip4ToIP6(ip, []byte{0, 0, 0, 0})
+}
+
+// IP4ToIP6Mapped returns the given v4 address as an RFC4291 "IPv4-mapped IPv6
+// address" (::ffff:a.b.c.d)
+func IP4ToIP6Mapped(ip net.IP) (net.IP, error) {
+	return ip4ToIP6(ip, []byte{0, 0, 0xff, 0xff})
+}
+
+// IP4ToIP6Translated returns the given v4 address as an RFC2765 "IPv4-
+// translated address" (::ffff:0:a.b.c.d), as used by Stateless IP/ICMP
+// Translation (SIIT)
+func IP4ToIP6Translated(ip net.IP) (net.IP, error) {
+	return ip4ToIP6(ip, []byte{0xff, 0xff, 0, 0})
+}
+
+// IP6ToIP4 returns the v4 address embedded in an IPv4-mapped, IPv4-compatible
+// or IPv4-translated v6 address. Unlike ForceIP4() it will not return a
+// value for any other v6 address, instead returning ErrNoEmbeddedIP4. The
+// unspecified address (::) and loopback address (::1) are not considered to
+// be IPv4-compatible
+func IP6ToIP4(ip net.IP) (net.IP, error) {
+	if len(ip) != 16 {
+		return nil, ErrNotIP6
+	}
+	if IsIP4Mapped(ip) || IsIP4Compatible(ip) || IsIP4Translated(ip) {
+		xip := make(net.IP, 4)
+		copy(xip, ip[12:])
+		return xip, nil
+	}
+	return nil, ErrNoEmbeddedIP4
+}
+
 // IPToBinaryString returns the given net.IP as a binary string
 func IPToBinaryString(ip net.IP) string {
 	var sa []string
@@ -400,6 +445,28 @@ func IPToBigint(ip net.IP) *big.Int {
 	return z
 }
 
+// IsIP4Compatible returns true if the given net.IP is an RFC4291 IPv4-
+// compatible IPv6 address, from the deprecated ::/96 block. The unspecified
+// (::) and loopback (::1) addresses are excluded
+func IsIP4Compatible(ip net.IP) bool {
+	if len(ip) != 16 || !isZeros(ip[:12]) {
+		return false
+	}
+	return binary.BigEndian.Uint32(ip[12:]) > 1
+}
+
+// IsIP4Mapped returns true if the given net.IP is a 16-byte RFC4291 IPv4-
+// mapped IPv6 address from the ::ffff:0:0/96 block
+func IsIP4Mapped(ip net.IP) bool {
+	return len(ip) == 16 && isZeros(ip[:10]) && ip[10] == 0xff && ip[11] == 0xff
+}
+
+// IsIP4Translated returns true if the given net.IP is an RFC2765 IPv4-
+// translated address from the ::ffff:0:0:0/96 block
+func IsIP4Translated(ip net.IP) bool {
+	return len(ip) == 16 && isZeros(ip[:8]) && ip[8] == 0xff && ip[9] == 0xff && isZeros(ip[10:12])
+}
+
 // NextIP returns a net.IP incremented by one from the input address. This
 // function is roughly as fast for v4 as IncrementIP4By(1) but is consistently
 // 4x faster on v6 than IncrementIP6By(1). The bundled tests provide
@@ -474,3 +541,22 @@ func generateNetLimits(version int, filler byte) net.IP {
 	}
 	return b
 }
+
+func ip4ToIP6(ip net.IP, tag []byte) (net.IP, error) {
+	if EffectiveVersion(ip) != 4 {
+		return nil, ErrNotIP4
+	}
+	xip := make(net.IP, 16)
+	copy(xip[8:], tag)
+	copy(xip[12:], ip.To4())
+	return xip, nil
+}
+
+func isZeros(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -1276,6 +1276,140 @@ func TestNet_ContainsNetwork(t *testing.T) {
 	}
 }
 
+var IP4EmbeddingTests = []struct {
+	ip4        string
+	mapped     string
+	compatible string
+	translated string
+}{
+	{
+		"192.168.1.1",
+		"::ffff:192.168.1.1",
+		"::192.168.1.1",
+		"::ffff:0:192.168.1.1",
+	},
+	{
+		"10.0.0.255",
+		"::ffff:10.0.0.255",
+		"::10.0.0.255",
+		"::ffff:0:10.0.0.255",
+	},
+}
+
+func TestIP4ToIP6Embeddings(t *testing.T) {
+	for i, tt := range IP4EmbeddingTests {
+		ip4 := net.ParseIP(tt.ip4)
+
+		mapped, _ := IP4ToIP6Mapped(ip4)
+		if CompareIPs(mapped, net.ParseIP(tt.mapped)) != 0 || !IsIP4Mapped(mapped) {
+			t.Errorf("[%d] IP4ToIP6Mapped expected %s got %s", i, tt.mapped, mapped)
+		}
+
+		compat, _ := IP4ToIP6Compatible(ip4)
+		if CompareIPs(compat, net.ParseIP(tt.compatible)) != 0 || !IsIP4Compatible(compat) {
+			t.Errorf("[%d] IP4ToIP6Compatible expected %s got %s", i, tt.compatible, compat)
+		}
+
+		trans, _ := IP4ToIP6Translated(ip4)
+		if CompareIPs(trans, net.ParseIP(tt.translated)) != 0 || !IsIP4Translated(trans) {
+			t.Errorf("[%d] IP4ToIP6Translated expected %s got %s", i, tt.translated, trans)
+		}
+
+		for _, s := range []string{tt.mapped, tt.compatible, tt.translated} {
+			xip, err := IP6ToIP4(net.ParseIP(s))
+			if err != nil {
+				t.Errorf("[%d] IP6ToIP4(%s) got unexpected error: %s", i, s, err)
+			} else if !xip.Equal(ip4) || len(xip) != 4 {
+				t.Errorf("[%d] IP6ToIP4(%s) expected %s got %s", i, s, tt.ip4, xip)
+			}
+		}
+	}
+
+	if _, err := IP4ToIP6Mapped(net.ParseIP("2001:db8::1")); err != ErrNotIP4 {
+		t.Errorf("IP4ToIP6Mapped expected ErrNotIP4 for v6 input, got '%v'", err)
+	}
+}
+
+var IP6ToIP4ErrorTests = []struct {
+	ip  net.IP
+	err error
+}{
+	{
+		net.ParseIP("2001:db8::c0a8:101"),
+		ErrNoEmbeddedIP4,
+	},
+	{
+		net.ParseIP("::"),
+		ErrNoEmbeddedIP4,
+	},
+	{
+		net.ParseIP("::1"),
+		ErrNoEmbeddedIP4,
+	},
+	{
+		net.IP{192, 168, 1, 1},
+		ErrNotIP6,
+	},
+}
+
+func TestIP6ToIP4Errors(t *testing.T) {
+	for i, tt := range IP6ToIP4ErrorTests {
+		if _, err := IP6ToIP4(tt.ip); err != tt.err {
+			t.Errorf("[%d] IP6ToIP4(%s) expected error '%v' got '%v'", i, tt.ip, tt.err, err)
+		}
+	}
+}
+
+func TestIP4NetToIP6Mapped(t *testing.T) {
+	_, n4, _ := ParseCIDR("10.0.0.0/8")
+	_, n6, _ := ParseCIDR("::ffff:10.0.0.0/104")
+
+	xn, err := IP4NetToIP6Mapped(n4)
+	if err != nil {
+		t.Fatalf("IP4NetToIP6Mapped got unexpected error: %s", err)
+	}
+	if ones, _ := xn.Mask.Size(); ones != 104 || xn.Version() != 6 || CompareIPs(xn.IP, n6.IP) != 0 {
+		t.Errorf("IP4NetToIP6Mapped expected %s/104 got %s", n6.IP, xn.String())
+	}
+
+	xn, err = IP6MappedNetToIP4(n6)
+	if err != nil {
+		t.Fatalf("IP6MappedNetToIP4 got unexpected error: %s", err)
+	}
+	if xn.String() != n4.String() || xn.Version() != 4 {
+		t.Errorf("IP6MappedNetToIP4 expected %s got %s", n4.String(), xn.String())
+	}
+
+	if xn, _ = IP4NetToIP6Mapped(n4); xn.String() != "::ffff:10.0.0.0/104" {
+		t.Errorf("expected the mapped network to print as ::ffff:10.0.0.0/104, got %s", xn.String())
+	}
+	if xn.Contains(net.IP{10, 1, 2, 3}) || !xn.Contains(net.ParseIP("::ffff:10.1.2.3")) || xn.Contains(net.ParseIP("::ffff:11.1.2.3")) {
+		t.Errorf("expected %s to contain only mapped addresses in 10.0.0.0/8", xn.String())
+	}
+	if sn, _ := xn.Supernet(103); sn.String() != "::ffff:10.0.0.0/103" {
+		t.Errorf("expected the supernet of %s to print as v6, got %s", xn.String(), sn.String())
+	}
+	if _, xn6, _ := ParseCIDR(xn.String()); !xn6.ContainsNet(xn) || !xn.ContainsNet(xn6) {
+		t.Errorf("expected %s to be the same network as the one it prints", xn.String())
+	}
+
+	// networks not built by IP4NetToIP6Mapped() behave as net.IPNet does
+	if n6.String() != "10.0.0.0/8" || !n6.Contains(net.IP{10, 1, 2, 3}) || !n6.Contains(net.ParseIP("::ffff:10.1.2.3")) {
+		t.Errorf("expected parsed %s to behave as net.IPNet", n6.String())
+	}
+	if nn := NewNet(net.ParseIP("::ffff:10.0.0.0"), 8); nn.String() != "10.0.0.0/8" || !nn.Contains(net.IP{10, 1, 2, 3}) {
+		t.Errorf("expected NewNet() of a mapped address to be v4, got %s", nn.String())
+	}
+
+	_, n6, _ = ParseCIDR("2001:db8::/32")
+	if _, err := IP6MappedNetToIP4(n6); err != ErrNoEmbeddedIP4 {
+		t.Errorf("IP6MappedNetToIP4 expected ErrNoEmbeddedIP4 got '%v'", err)
+	}
+	if _, err := IP4NetToIP6Mapped(n6); err != ErrNotIP4 {
+		t.Errorf("IP4NetToIP6Mapped expected ErrNotIP4 got '%v'", err)
+	}
+}
+
 func compareNetArraysToStringRepresentation(a []Net, b []string) bool {
 	if len(a) != len(b) {
 		return false
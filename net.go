@@ -1,10 +1,10 @@
 package iplib
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"net"
-	"strings"
 )
 
 // Net extends net.IPNet adding a few useful features along the way
@@ -12,6 +12,10 @@ type Net struct {
 	net.IPNet
 	version int
 	length  int
+
+	// mapped is set on networks returned by IP4NetToIP6Mapped(), and those
+	// derived from them, so that they print and match as v6
+	mapped bool
 }
 
 // NewNet returns a new Net object containing ip at the specified masklen.
@@ -31,6 +35,35 @@ func NewNet(ip net.IP, masklen int) Net {
 	return Net{IPNet: n, version: version, length: length}
 }
 
+// IP4NetToIP6Mapped takes a v4 Net and returns the equivalent block of
+// RFC4291 IPv4-mapped IPv6 addresses, so 10.0.0.0/8 becomes
+// ::ffff:10.0.0.0/104. Unlike a network parsed from that string, which Go
+// treats as v4, the result and any Supernet() of it within ::ffff:0:0/96 are
+// v6 throughout: String() returns them in the mapped form and Contains() will
+// not match 4-byte v4 addresses, though as with any net.IP a 16-byte v4
+// address is the same as its mapped form
+func IP4NetToIP6Mapped(n Net) (Net, error) {
+	if n.version != 4 {
+		return Net{}, ErrNotIP4
+	}
+	ip, _ := IP4ToIP6Mapped(n.NetworkAddress())
+	ones, _ := n.Mask.Size()
+	mask := net.CIDRMask(ones+96, 128)
+	return Net{net.IPNet{IP: ip, Mask: mask}, 6, 16, true}, nil
+}
+
+// IP6MappedNetToIP4 takes a Net within the RFC4291 IPv4-mapped IPv6 block
+// ::ffff:0:0/96 and returns the equivalent v4 Net, so ::ffff:10.0.0.0/104
+// becomes 10.0.0.0/8. Networks outside of the mapped block, or larger than
+// it, will return ErrNoEmbeddedIP4
+func IP6MappedNetToIP4(n Net) (Net, error) {
+	ones, _ := n.Mask.Size()
+	if n.version != 6 || ones < 96 || !IsIP4Mapped(n.NetworkAddress()) {
+		return Net{}, ErrNoEmbeddedIP4
+	}
+	return NewNet(n.NetworkAddress()[12:], ones-96), nil
+}
+
 // NewNetBetween takes two net.IP's as input and will return the largest
 // netblock that can fit between them (exclusive of the IP's themselves).
 // If there is an exact fit it will set a boolean to true, otherwise the bool
@@ -75,18 +108,19 @@ func NewNetBetween(a, b net.IP) (Net, bool, error) {
 // and this function exposes it: net.ParseCIDR *always* returns an IPv6
 // address; if given a v4 address it returns the RFC4291 IPv4-mapped IPv6
 // address internally, but treats it like v4 in practice. In contrast
-// iplib.ParseCIDR will re-encode it as a v4
+// iplib.ParseCIDR will re-encode it as a v4. Networks written in IPv4-mapped
+// notation (::ffff:10.0.0.0/104) have a v6 mask and are left as v6
 func ParseCIDR(s string) (net.IP, Net, error) {
 	ip, ipnet, err := net.ParseCIDR(s)
 	if err != nil {
 		return ip, Net{}, err
 	}
-	if strings.Contains(s, ".") {
+	if len(ipnet.Mask) == net.IPv4len {
 		masklen, _ := ipnet.Mask.Size()
 		return ip[12:], NewNet(ip.To4(), masklen), err
 	}
 
-	return ip, Net{*ipnet, 6, 16}, err
+	return ip, Net{IPNet: *ipnet, version: 6, length: 16}, err
 }
 
 // BroadcastAddress returns the broadcast address for the represented network.
@@ -97,6 +131,20 @@ func (n Net) BroadcastAddress() net.IP {
 	return a
 }
 
+// Contains returns true if ip is part of the represented network. It is
+// net.IPNet.Contains() except for networks returned by IP4NetToIP6Mapped(),
+// which net.IPNet would treat as v4: those only contain 16-byte addresses, so
+// a v6 ACL entry for ::ffff:0:0/96 does not match 4-byte v4 addresses
+func (n Net) Contains(ip net.IP) bool {
+	if !n.mapped || !IsIP4Mapped(n.IP) {
+		return n.IPNet.Contains(ip)
+	}
+	if len(ip) != net.IPv6len {
+		return false
+	}
+	return n.IP.Equal(ip.Mask(n.Mask))
+}
+
 // ContainsNet returns true if the given Net is contained within the
 // represented block
 func (n Net) ContainsNet(network Net) bool {
@@ -324,11 +372,11 @@ func (n Net) Subnet(masklen int) ([]Net, error) {
 	}
 
 	mask := net.CIDRMask(masklen, all)
-	netlist := []Net{{net.IPNet{n.NetworkAddress(), mask}, n.version, n.length}}
+	netlist := []Net{{net.IPNet{IP: n.NetworkAddress(), Mask: mask}, n.version, n.length, n.mapped}}
 
 	for CompareIPs(netlist[len(netlist)-1].BroadcastAddress(), n.BroadcastAddress()) == -1 {
 		ng := net.IPNet{IP: NextIP(netlist[len(netlist)-1].BroadcastAddress()), Mask: mask}
-		netlist = append(netlist, Net{ng, n.version, n.length})
+		netlist = append(netlist, Net{ng, n.version, n.length, n.mapped})
 	}
 	return netlist, nil
 }
@@ -353,7 +401,19 @@ func (n Net) Supernet(masklen int) (Net, error) {
 
 	mask := net.CIDRMask(masklen, all)
 	ng := net.IPNet{IP: n.IP.Mask(mask), Mask: mask}
-	return Net{ng, n.version, n.length}, nil
+	return Net{ng, n.version, n.length, n.mapped}, nil
+}
+
+// String returns the CIDR notation of the represented network. It is
+// net.IPNet.String() except for networks returned by IP4NetToIP6Mapped(),
+// which are written in their v6 form (::ffff:10.0.0.0/104) rather than as the
+// v4 network net.IPNet would print (10.0.0.0/8)
+func (n Net) String() string {
+	ones, bits := n.Mask.Size()
+	if !n.mapped || bits != 128 || !IsIP4Mapped(n.IP) {
+		return n.IPNet.String()
+	}
+	return fmt.Sprintf("::ffff:%s/%d", n.IP[12:].String(), ones)
 }
 
 // Version returns the version of IP for the enclosed netblock, Either 4 or 6.
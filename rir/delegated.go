@@ -0,0 +1,327 @@
+package rir
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+// Additional statuses used in RIR delegated statistics files, alongside
+// StatusAllocated and StatusReserved
+const (
+	// StatusAssigned marks a block assigned by an RIR directly to an end
+	// user rather than to a LIR or ISP
+	StatusAssigned Status = "ASSIGNED"
+
+	// StatusAvailable marks a block held by an RIR but not yet delegated
+	StatusAvailable Status = "AVAILABLE"
+)
+
+var (
+	ErrBadDelegatedRecord  = errors.New("delegated statistics record is malformed")
+	ErrBadDelegatedVersion = errors.New("delegated statistics file has no version line")
+)
+
+// DelegatedStats holds the contents of an RIR delegated statistics file, in
+// the delegated-<registry>-extended-<date> format published by AFRINIC,
+// APNIC, ARIN, LACNIC and the RIPE NCC. The header fields are taken from the
+// file's version line
+type DelegatedStats struct {
+	// Version is the version of the file format, such as "2.3"
+	Version string
+
+	// Registry is the RIR that published the file, in lower case as it
+	// appears in the file, such as "ripencc"
+	Registry string
+
+	// Serial is the serial number of the file
+	Serial string
+
+	// Records is the number of records the version line claims the file
+	// holds, excluding the header and summary lines
+	Records int
+
+	// StartDate and EndDate are the dates of the earliest and latest record
+	// in the file
+	StartDate time.Time
+	EndDate   time.Time
+
+	// UTCOffset is the offset from UTC of the registry's local time, such as
+	// "+0100"
+	UTCOffset string
+
+	// Summary maps each record type ("asn", "ipv4" or "ipv6") to the number
+	// of records of that type the summary lines claim the file holds
+	Summary map[string]int
+
+	// Delegations are the ipv4 and ipv6 records from the file, in the order
+	// they appear. asn records are not included
+	Delegations []*Delegation
+
+	index *DelegationIndex
+}
+
+// Delegation is a single ipv4 or ipv6 record from a delegated statistics
+// file
+type Delegation struct {
+	// Registry is the RIR responsible for the block, in lower case as it
+	// appears in the file
+	Registry string
+
+	// CountryCode is the ISO 3166 2-letter code of the country the holder
+	// of the block is in. It is empty for blocks that are available or
+	// reserved
+	CountryCode string
+
+	// Networks holds the block. An ipv4 record is given as a start address
+	// and count of addresses which need not be a CIDR block, so it may be
+	// split into several networks. An ipv6 record is always a single network
+	Networks []iplib.Net
+
+	// Date is the date the block was delegated, or the zero time if there
+	// is none
+	Date time.Time
+
+	// Status is the status of the block
+	Status Status
+
+	// OpaqueID identifies the holder of the block. It is the same in every
+	// record for a given holder, so it can be used to group their resources
+	OpaqueID string
+
+	// Extensions holds any fields following the opaque-id
+	Extensions []string
+}
+
+// LoadDelegatedFile reads an RIR delegated statistics file from the named
+// path
+func LoadDelegatedFile(path string) (*DelegatedStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseDelegated(f)
+}
+
+// ParseDelegated reads an RIR delegated statistics file from r. Comment and
+// blank lines are skipped, as are asn records. The plain delegated format,
+// which lacks the opaque-id, is also accepted
+func ParseDelegated(r io.Reader) (*DelegatedStats, error) {
+	d := &DelegatedStats{Summary: make(map[string]int)}
+	version := false
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "|")
+
+		if !version {
+			if err := d.parseVersion(fields); err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			version = true
+			continue
+		}
+
+		if len(fields) >= 6 && fields[1] == "*" && fields[5] == "summary" {
+			count, err := strconv.Atoi(fields[4])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, ErrBadDelegatedRecord)
+			}
+			d.Summary[fields[2]] = count
+			continue
+		}
+
+		if len(fields) >= 3 && fields[2] == "asn" {
+			continue
+		}
+
+		del, err := parseDelegation(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		d.Delegations = append(d.Delegations, del)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if !version {
+		return nil, ErrBadDelegatedVersion
+	}
+
+	d.index = NewDelegationIndex(d.Delegations)
+	return d, nil
+}
+
+// GetDelegationForIP returns the delegation the supplied IP is part of, or
+// nil if there is none
+func (d *DelegatedStats) GetDelegationForIP(ip net.IP) *Delegation {
+	return d.index.GetDelegationForIP(ip)
+}
+
+func (d *DelegatedStats) parseVersion(fields []string) error {
+	if len(fields) < 7 {
+		return ErrBadDelegatedVersion
+	}
+	records, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return ErrBadDelegatedVersion
+	}
+
+	d.Version = fields[0]
+	d.Registry = fields[1]
+	d.Serial = fields[2]
+	d.Records = records
+	d.StartDate, _ = parseDelegatedDate(fields[4])
+	d.EndDate, _ = parseDelegatedDate(fields[5])
+	d.UTCOffset = fields[6]
+	return nil
+}
+
+func parseDelegation(fields []string) (*Delegation, error) {
+	if len(fields) < 7 {
+		return nil, ErrBadDelegatedRecord
+	}
+
+	date, err := parseDelegatedDate(fields[5])
+	if err != nil {
+		return nil, err
+	}
+	del := &Delegation{
+		Registry:    fields[0],
+		CountryCode: fields[1],
+		Date:        date,
+		Status:      Status(strings.ToUpper(fields[6])),
+	}
+	if len(fields) > 7 {
+		del.OpaqueID = fields[7]
+	}
+	if len(fields) > 8 {
+		del.Extensions = fields[8:]
+	}
+
+	start := net.ParseIP(fields[3])
+	value, err := strconv.ParseUint(fields[4], 10, 64)
+	if start == nil || err != nil {
+		return nil, ErrBadDelegatedRecord
+	}
+
+	switch fields[2] {
+	case "ipv4":
+		if start.To4() == nil || value == 0 || uint64(iplib.IP4ToUint32(start))+value > 1<<32 {
+			return nil, ErrBadDelegatedRecord
+		}
+		del.Networks = rangeToNets(iplib.IP4ToUint32(start), value)
+	case "ipv6":
+		if start.To4() != nil || value > 128 {
+			return nil, ErrBadDelegatedRecord
+		}
+		del.Networks = []iplib.Net{iplib.NewNet(start, int(value))}
+	default:
+		return nil, ErrBadDelegatedRecord
+	}
+	return del, nil
+}
+
+// parseDelegatedDate reads a YYYYMMDD date. Empty and all-zero values, used
+// for records with no date, produce the zero time
+func parseDelegatedDate(s string) (time.Time, error) {
+	if s == "" || strings.Trim(s, "0") == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("20060102", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s'", s)
+	}
+	return t, nil
+}
+
+// rangeToNets returns the smallest list of CIDR blocks covering count v4
+// addresses beginning at start
+func rangeToNets(start uint32, count uint64) []iplib.Net {
+	nets := []iplib.Net{}
+	for count > 0 {
+		size := uint(bits.TrailingZeros32(start))
+		if start == 0 {
+			size = 32
+		}
+		for uint64(1)<<size > count {
+			size--
+		}
+		nets = append(nets, iplib.NewNet(iplib.Uint32ToIP4(start), 32-int(size)))
+		start += uint32(uint64(1) << size)
+		count -= uint64(1) << size
+	}
+	return nets
+}
+
+// DelegationIndex answers lookups by IP across a set of delegations, which
+// may come from several delegated statistics files. Delegations are expected
+// not to overlap, as is the case for the files published by the RIRs
+type DelegationIndex struct {
+	v4 []delegationEntry
+	v6 []delegationEntry
+}
+
+type delegationEntry struct {
+	network    iplib.Net
+	delegation *Delegation
+}
+
+// NewDelegationIndex returns a DelegationIndex built from the supplied
+// delegations. To search every RIR at once append the Delegations from each
+// of their files together
+func NewDelegationIndex(delegations []*Delegation) *DelegationIndex {
+	idx := &DelegationIndex{}
+	for _, del := range delegations {
+		for _, n := range del.Networks {
+			e := delegationEntry{network: n, delegation: del}
+			if n.Version() == 4 {
+				idx.v4 = append(idx.v4, e)
+			} else {
+				idx.v6 = append(idx.v6, e)
+			}
+		}
+	}
+	for _, list := range [][]delegationEntry{idx.v4, idx.v6} {
+		sort.Slice(list, func(i, j int) bool {
+			return iplib.CompareNets(list[i].network, list[j].network) < 0
+		})
+	}
+	return idx
+}
+
+// GetDelegationForIP returns the delegation the supplied IP is part of, or
+// nil if there is none
+func (idx *DelegationIndex) GetDelegationForIP(ip net.IP) *Delegation {
+	if idx == nil {
+		return nil
+	}
+
+	list := idx.v6
+	if iplib.EffectiveVersion(ip) == 4 {
+		list = idx.v4
+	}
+	i := sort.Search(len(list), func(i int) bool {
+		return iplib.CompareIPs(list[i].network.IP, ip) > 0
+	})
+	if i > 0 && list[i-1].network.Contains(ip) {
+		return list[i-1].delegation
+	}
+	return nil
+}
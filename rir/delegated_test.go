@@ -0,0 +1,120 @@
+package rir
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+func TestLoadDelegatedFile(t *testing.T) {
+	d, err := LoadDelegatedFile("testdata/delegated-ripencc-extended-latest")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if d.Version != "2.3" || d.Registry != "ripencc" || d.Serial != "1710460800" || d.Records != 9 || d.UTCOffset != "+0100" {
+		t.Errorf("unexpected header %s|%s|%s|%d|%s", d.Version, d.Registry, d.Serial, d.Records, d.UTCOffset)
+	}
+	if d.StartDate.Format("20060102") != "19830705" || d.EndDate.Format("20060102") != "20240314" {
+		t.Errorf("unexpected dates %s - %s", d.StartDate, d.EndDate)
+	}
+	if d.Summary["asn"] != 2 || d.Summary["ipv4"] != 5 || d.Summary["ipv6"] != 2 {
+		t.Errorf("unexpected summary %v", d.Summary)
+	}
+	if l := len(d.Delegations); l != 7 {
+		t.Fatalf("expected 7 delegations, got %d", l)
+	}
+
+	v6 := d.Delegations[5]
+	if v6.Networks[0].String() != "2001:600::/32" || v6.OpaqueID != "1b1a9c1b-2f1d-4a3e-9a56-76c2d3a9f0b1" || len(v6.Extensions) != 1 {
+		t.Errorf("unexpected v6 delegation %s %s %v", v6.Networks[0].String(), v6.OpaqueID, v6.Extensions)
+	}
+	if d.Delegations[3].Status != StatusAvailable || !d.Delegations[3].Date.IsZero() {
+		t.Error("expected available delegation with no date")
+	}
+}
+
+var RangeToNetsTests = []struct {
+	start string
+	count uint64
+	nets  []string
+}{
+	{"2.0.0.0", 1048576, []string{"2.0.0.0/12"}},
+	{"2.16.0.0", 768, []string{"2.16.0.0/23", "2.16.2.0/24"}},
+	{"10.0.0.1", 6, []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+	{"0.0.0.0", 1 << 32, []string{"0.0.0.0/0"}},
+	{"255.255.255.255", 1, []string{"255.255.255.255/32"}},
+}
+
+func TestRangeToNets(t *testing.T) {
+	for _, tt := range RangeToNetsTests {
+		nets := rangeToNets(iplib.IP4ToUint32(net.ParseIP(tt.start)), tt.count)
+		if len(nets) != len(tt.nets) {
+			t.Errorf("%s+%d: expected %d networks, got %d", tt.start, tt.count, len(tt.nets), len(nets))
+			continue
+		}
+		for i, n := range nets {
+			if n.String() != tt.nets[i] {
+				t.Errorf("%s+%d: expected %s at %d, got %s", tt.start, tt.count, tt.nets[i], i, n.String())
+			}
+		}
+	}
+}
+
+var DelegationIPTests = []struct {
+	address  string
+	country  string
+	status   Status
+	opaqueID string
+}{
+	{"2.1.2.3", "FR", StatusAllocated, "0c94f1c0-7bce-4c2a-a3d7-2d7c2a8f2a40"},
+	{"2.16.2.200", "GB", StatusAssigned, "1b1a9c1b-2f1d-4a3e-9a56-76c2d3a9f0b1"},
+	{"2.16.3.1", "DE", StatusAllocated, "2f5e6f70-4d4c-4d2b-9f4b-6a6f4e8c9d21"},
+	{"2.57.7.255", "ZZ", StatusReserved, "ripencc"},
+	{"2001:617::1", "NL", StatusAllocated, "3a9e7c11-5d2e-4f33-8d3b-1a2b3c4d5e6f"},
+	{"2.16.4.1", "", "", ""},
+	{"2001:620::1", "", "", ""},
+}
+
+func TestDelegatedStats_GetDelegationForIP(t *testing.T) {
+	d, _ := LoadDelegatedFile("testdata/delegated-ripencc-extended-latest")
+	for _, tt := range DelegationIPTests {
+		del := d.GetDelegationForIP(net.ParseIP(tt.address))
+		if tt.status == "" {
+			if del != nil {
+				t.Errorf("%s: expected no delegation, got %s", tt.address, del.Networks[0].String())
+			}
+			continue
+		}
+		if del == nil {
+			t.Errorf("%s: expected a delegation", tt.address)
+			continue
+		}
+		if del.CountryCode != tt.country || del.Status != tt.status || del.OpaqueID != tt.opaqueID {
+			t.Errorf("%s: expected %s %s %s, got %s %s %s", tt.address, tt.country, tt.status, tt.opaqueID, del.CountryCode, del.Status, del.OpaqueID)
+		}
+	}
+}
+
+var ParseDelegatedErrorTests = []struct {
+	name string
+	data string
+}{
+	{"NoVersion", "# nothing here\n"},
+	{"BadVersion", "2.3|ripencc\n"},
+	{"ShortRecord", "2.3|ripencc|1|1|19830705|20240314|+0100\nripencc|FR|ipv4|2.0.0.0\n"},
+	{"BadStart", "2.3|ripencc|1|1|19830705|20240314|+0100\nripencc|FR|ipv4|2.0.0|256|20100712|allocated\n"},
+	{"V4Overflow", "2.3|ripencc|1|1|19830705|20240314|+0100\nripencc|FR|ipv4|255.255.255.0|512|20100712|allocated\n"},
+	{"BadPrefix", "2.3|ripencc|1|1|19830705|20240314|+0100\nripencc|FR|ipv6|2001:600::|129|20100712|allocated\n"},
+	{"BadDate", "2.3|ripencc|1|1|19830705|20240314|+0100\nripencc|FR|ipv6|2001:600::|32|2010-07-12|allocated\n"},
+}
+
+func TestParseDelegatedErrors(t *testing.T) {
+	for _, tt := range ParseDelegatedErrorTests {
+		if _, err := ParseDelegated(strings.NewReader(tt.data)); err == nil {
+			t.Errorf("'%s' expected an error", tt.name)
+		}
+	}
+}
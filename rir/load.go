@@ -0,0 +1,208 @@
+package rir
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	ErrMissingColumn = errors.New("registry CSV is missing a required column")
+	ErrUnknownFormat = errors.New("registry file is neither XML nor CSV")
+)
+
+var footnoteRE = regexp.MustCompile(`\[\d+\]`)
+
+// csvColumns are the column headers, as published by IANA, needed to build
+// an Allocation
+var csvColumns = []string{
+	"Prefix",
+	"Designation",
+	"Date",
+	"WHOIS",
+	"Status",
+}
+
+// whoisRIRs maps each RIR's WHOIS server to the RIR
+var whoisRIRs = map[string]string{
+	"whois.afrinic.net": AFRINIC,
+	"whois.apnic.net":   APNIC,
+	"whois.arin.net":    ARIN,
+	"whois.lacnic.net":  LACNIC,
+	"whois.ripe.net":    RIPENCC,
+}
+
+type xmlRecord struct {
+	Prefix      string `xml:"prefix"`
+	Designation string `xml:"designation"`
+	Date        string `xml:"date"`
+	Whois       string `xml:"whois"`
+	Status      string `xml:"status"`
+}
+
+// LoadFile reads an IANA address space registry from the named file, which
+// must be either the XML or CSV version of the ipv4-address-space or
+// ipv6-unicast-address-assignments registry as published by IANA. The format
+// is determined from the file extension.
+func LoadFile(path string) ([]*Allocation, error) {
+	var load func(io.Reader) ([]*Allocation, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		load = LoadXML
+	case ".csv":
+		load = LoadCSV
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return load(f)
+}
+
+// LoadCSV reads the CSV version of an IANA address space registry from r and
+// returns it as a list of allocations. Columns are matched by their header,
+// ignoring footnotes, so their order does not matter, but the Prefix,
+// Designation, Date, WHOIS and Status columns must be present or
+// ErrMissingColumn will be returned.
+func LoadCSV(r io.Reader) ([]*Allocation, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make(map[string]int)
+	for i, h := range header {
+		cols[cleanField(h)] = i
+	}
+	for _, c := range csvColumns {
+		if _, ok := cols[c]; !ok {
+			return nil, ErrMissingColumn
+		}
+	}
+
+	allocations := []*Allocation{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i := cols[name]; i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		}
+
+		a, err := newAllocation(field("Prefix"), field("Designation"), field("Date"), field("WHOIS"), field("Status"))
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+	return allocations, nil
+}
+
+// LoadXML reads the XML version of an IANA address space registry from r and
+// returns it as a list of allocations.
+func LoadXML(r io.Reader) ([]*Allocation, error) {
+	allocations := []*Allocation{}
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "record" {
+			continue
+		}
+
+		var rec xmlRecord
+		if err := dec.DecodeElement(&rec, &se); err != nil {
+			return nil, err
+		}
+
+		a, err := newAllocation(rec.Prefix, rec.Designation, rec.Date, rec.Whois, rec.Status)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+	return allocations, nil
+}
+
+func newAllocation(prefix, designation, date, whois, status string) (*Allocation, error) {
+	n, err := parsePrefix(cleanField(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Allocation{
+		Network:     n,
+		Designation: cleanField(designation),
+		Whois:       cleanField(whois),
+		Status:      Status(strings.ToUpper(cleanField(status))),
+	}
+	a.RIR = whoisRIRs[strings.ToLower(a.Whois)]
+
+	if d := cleanField(date); d != "" {
+		a.Date, err = time.Parse("2006-01", d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date '%s'", d)
+		}
+	}
+	return a, nil
+}
+
+// parsePrefix reads a prefix from the registry. v6 prefixes are in CIDR
+// notation, v4 ones are given as the first octet and mask, for example
+// "001/8"
+func parsePrefix(s string) (iplib.Net, error) {
+	if !strings.Contains(s, ":") {
+		parts := strings.Split(s, "/")
+		if len(parts) == 2 {
+			if i, err := strconv.Atoi(parts[0]); err == nil && i >= 0 && i <= 255 {
+				s = fmt.Sprintf("%d.0.0.0/%s", i, parts[1])
+			}
+		}
+	}
+
+	_, n, err := iplib.ParseCIDR(s)
+	if err != nil {
+		return iplib.Net{}, fmt.Errorf("invalid prefix '%s': %s", s, err)
+	}
+	return n, nil
+}
+
+// cleanField strips footnote references and surplus whitespace from a value
+// read from the registry
+func cleanField(s string) string {
+	s = footnoteRE.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
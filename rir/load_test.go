@@ -0,0 +1,66 @@
+package rir
+
+import (
+	"strings"
+	"testing"
+)
+
+var LoadFileTests = []struct {
+	file  string
+	count int
+	first string
+	last  string
+}{
+	{"testdata/ipv4-address-space.csv", 6, "0.0.0.0/8", "224.0.0.0/8"},
+	{"testdata/ipv6-unicast-address-assignments.xml", 4, "2001::/23", "2600::/12"},
+}
+
+func TestLoadFile(t *testing.T) {
+	for _, tt := range LoadFileTests {
+		allocations, err := LoadFile(tt.file)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.file, err)
+			continue
+		}
+		if len(allocations) != tt.count {
+			t.Errorf("%s: expected %d allocations, got %d", tt.file, tt.count, len(allocations))
+			continue
+		}
+		if s := allocations[0].Network.String(); s != tt.first {
+			t.Errorf("%s: expected first allocation %s, got %s", tt.file, tt.first, s)
+		}
+		if s := allocations[tt.count-1].Network.String(); s != tt.last {
+			t.Errorf("%s: expected last allocation %s, got %s", tt.file, tt.last, s)
+		}
+
+		r := NewRegistry(allocations)
+		for _, a := range allocations {
+			xa := DefaultRegistry.GetAllocationsForNetwork(a.Network)
+			if len(xa) != 1 {
+				t.Errorf("%s: expected 1 embedded allocation for %s, got %d", tt.file, a.Network.String(), len(xa))
+				continue
+			}
+			if xa[0].Designation != a.Designation || xa[0].RIR != a.RIR || xa[0].Status != a.Status || xa[0].Whois != a.Whois || !xa[0].Date.Equal(a.Date) {
+				t.Errorf("%s: loaded %s does not match embedded data", tt.file, a.Network.String())
+			}
+			if r.GetAllocationForIP(a.Network.IP) != a {
+				t.Errorf("%s: expected registry lookup to find %s", tt.file, a.Network.String())
+			}
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := LoadFile("testdata/ipv4-address-space.txt"); err != ErrUnknownFormat {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+	if _, err := LoadCSV(strings.NewReader("Prefix,Designation,Date\n001/8,APNIC,2010-01\n")); err != ErrMissingColumn {
+		t.Errorf("expected ErrMissingColumn, got %v", err)
+	}
+	if _, err := LoadCSV(strings.NewReader("Prefix,Designation,Date,WHOIS,Status\n300/8,APNIC,2010-01,,ALLOCATED\n")); err == nil {
+		t.Error("expected error for invalid prefix")
+	}
+	if _, err := LoadXML(strings.NewReader("<registry><record><prefix>001/8</prefix><date>Jan 2010</date></record></registry>")); err == nil {
+		t.Error("expected error for invalid date")
+	}
+}
@@ -0,0 +1,195 @@
+package rir
+
+import (
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/kenits/iplib"
+)
+
+var (
+	ip4Space      = getFromCIDR("0.0.0.0/0")
+	globalUnicast = getFromCIDR("2000::/3")
+)
+
+// Registry is a queryable set of allocations from IANA's address space
+// registries. Allocations may be nested, as IANA's own 3ffe::/16 is within
+// 3000::/4, in which case the most specific allocation containing an address
+// is the one it belongs to. They are kept sorted so that lookups start with
+// a binary search. A Registry is safe for concurrent use.
+type Registry struct {
+	mu sync.RWMutex
+	v4 []*Allocation
+	v6 []*Allocation
+}
+
+// NewRegistry returns a new Registry containing the supplied allocations.
+// It is typically given the output of LoadFile(), LoadCSV() or LoadXML(), or
+// a list built by hand
+func NewRegistry(allocations []*Allocation) *Registry {
+	r := &Registry{}
+	r.Merge(allocations...)
+	return r
+}
+
+// Clone returns a new Registry containing the same allocations as this one.
+// Changes made to the clone with Merge() do not affect the original
+func (r *Registry) Clone() *Registry {
+	return NewRegistry(r.Allocations())
+}
+
+// Merge adds the supplied allocations to the registry. Any existing
+// allocation overlapping one being added is removed, so merging a freshly
+// loaded data-set into a clone of DefaultRegistry will replace blocks IANA
+// has changed, including those it has split or aggregated, while keeping the
+// rest. Allocations supplied together are all kept, even where they nest,
+// other than an exact duplicate which replaces the one before it
+func (r *Registry) Merge(allocations ...*Allocation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range allocations {
+		list := r.list(a.Network.Version())
+
+		xlist := (*list)[:0]
+		for _, xa := range *list {
+			if !xa.Network.ContainsNet(a.Network) && !a.Network.ContainsNet(xa.Network) {
+				xlist = append(xlist, xa)
+			}
+		}
+		*list = xlist
+	}
+
+	for _, a := range allocations {
+		list := r.list(a.Network.Version())
+
+		xlist := *list
+		i := sort.Search(len(xlist), func(i int) bool {
+			return iplib.CompareNets(xlist[i].Network, a.Network) >= 0
+		})
+		if i < len(xlist) && iplib.CompareNets(xlist[i].Network, a.Network) == 0 {
+			xlist[i] = a
+			continue
+		}
+		xlist = append(xlist, nil)
+		copy(xlist[i+1:], xlist[i:])
+		xlist[i] = a
+		*list = xlist
+	}
+}
+
+// Allocations returns a list of every allocation in the registry, v4 first,
+// in address order
+func (r *Registry) Allocations() []*Allocation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	allocations := make([]*Allocation, 0, len(r.v4)+len(r.v6))
+	allocations = append(allocations, r.v4...)
+	return append(allocations, r.v6...)
+}
+
+// GetAllocationForIP returns the most specific allocation the supplied IP is
+// part of, or nil if it is not in an allocated block
+func (r *Registry) GetAllocationForIP(ip net.IP) *Allocation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := *r.list(iplib.EffectiveVersion(ip))
+	i := sort.Search(len(list), func(i int) bool {
+		return iplib.CompareIPs(list[i].Network.IP, ip) > 0
+	})
+
+	// any allocation nested within another sorts after it, so the first one
+	// found containing ip working backward is the most specific
+	for i--; i >= 0; i-- {
+		if list[i].Network.Contains(ip) {
+			return list[i]
+		}
+	}
+	return nil
+}
+
+// GetAllocationsForNetwork returns a list of any allocations in the registry
+// that are either part of the supplied network or that the supplied network
+// is part of, in address order
+func (r *Registry) GetAllocationsForNetwork(n iplib.Net) []*Allocation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	allocations := []*Allocation{}
+	list := *r.list(n.Version())
+	i := sort.Search(len(list), func(i int) bool {
+		return iplib.CompareIPs(list[i].Network.IP, n.IP) >= 0
+	})
+	for j := 0; j < i; j++ {
+		if list[j].Network.ContainsNet(n) {
+			allocations = append(allocations, list[j])
+		}
+	}
+	for ; i < len(list); i++ {
+		if !list[i].Network.ContainsNet(n) && !n.ContainsNet(list[i].Network) {
+			break
+		}
+		allocations = append(allocations, list[i])
+	}
+	return allocations
+}
+
+// Unallocated returns the networks of the given IP version that IANA has not
+// allocated, which are those with neither StatusAllocated nor StatusLegacy.
+// For v4 this covers the whole address space, for v6 only 2000::/3 since
+// that is all IANA's registry describes. The networks are as large as
+// possible and in address order
+func (r *Registry) Unallocated(version int) []iplib.Net {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	allocated := []iplib.Net{}
+	for _, a := range *r.list(version) {
+		if a.Status == StatusAllocated || a.Status == StatusLegacy {
+			allocated = append(allocated, a.Network)
+		}
+	}
+
+	space := globalUnicast
+	if version == 4 {
+		space = ip4Space
+	}
+	return unallocatedIn(space, allocated)
+}
+
+func (r *Registry) list(version int) *[]*Allocation {
+	if version == 4 {
+		return &r.v4
+	}
+	return &r.v6
+}
+
+// unallocatedIn returns the parts of n not covered by any of the allocated
+// networks by halving n until each half is either entirely allocated or
+// entirely free
+func unallocatedIn(n iplib.Net, allocated []iplib.Net) []iplib.Net {
+	overlap := false
+	for _, a := range allocated {
+		if a.ContainsNet(n) {
+			return []iplib.Net{}
+		}
+		if n.ContainsNet(a) {
+			overlap = true
+			break
+		}
+	}
+	if !overlap {
+		return []iplib.Net{n}
+	}
+
+	ones, _ := n.Mask.Size()
+	halves, _ := n.Subnet(ones + 1)
+	nets := []iplib.Net{}
+	for _, h := range halves {
+		nets = append(nets, unallocatedIn(h, allocated)...)
+	}
+	return nets
+}
@@ -0,0 +1,423 @@
+/*
+Package rir imports the Internet Assigned Numbers Authority (IANA) IPv4
+Address Space and IPv6 Global Unicast Address Assignments registries as a data
+structure and implements functions to find which Regional Internet Registry
+(RIR) an IP address or iplib.Net belongs to. Where the iana package describes
+the special-purpose blocks, this one describes how the rest of the address
+space has been handed out: each v4 /8 and each v6 block IANA has assigned
+from 2000::/3, with the RIR responsible for it, the WHOIS server for further
+detail and its status.
+
+The data-set for the IANA registries is available from:
+
+- https://www.iana.org/assignments/ipv4-address-space/ipv4-address-space.xhtml
+
+- https://www.iana.org/assignments/ipv6-unicast-address-assignments/ipv6-unicast-address-assignments.xhtml
+*/
+package rir
+
+import (
+	"net"
+	"time"
+
+	"github.com/kenits/iplib"
+)
+
+// Status is the state of an allocation as given by IANA
+type Status string
+
+const (
+	// StatusAllocated marks a block IANA has allocated to an RIR
+	StatusAllocated Status = "ALLOCATED"
+
+	// StatusLegacy marks a v4 block that was assigned before the RIRs
+	// existed, they are now administered by one of the RIRs
+	StatusLegacy Status = "LEGACY"
+
+	// StatusReserved marks a block held back by IANA, for example for
+	// multicast or private use
+	StatusReserved Status = "RESERVED"
+)
+
+// The five Regional Internet Registries, as they appear in Allocation.RIR
+const (
+	AFRINIC = "AFRINIC"
+	APNIC   = "APNIC"
+	ARIN    = "ARIN"
+	LACNIC  = "LACNIC"
+	RIPENCC = "RIPE NCC"
+)
+
+// DefaultRegistry holds the aggregated allocation list from IANA's v4 and v6
+// registries and is used by all of the package-level functions. The
+// following fields were imported: Prefix, Designation, Date, WHOIS and
+// Status
+var DefaultRegistry *Registry
+
+// Allocation describes an entry in the IANA IPv4 Address Space or IPv6
+// Global Unicast Address Assignments registry
+type Allocation struct {
+
+	// Network is the allocated network
+	Network iplib.Net
+
+	// Designation is who IANA lists as holding the block. For allocated
+	// blocks this is an RIR, for legacy blocks it may be an organization or
+	// "Administered by" an RIR
+	Designation string
+
+	// RIR is the Regional Internet Registry responsible for the block, or
+	// the empty string if there is none
+	RIR string
+
+	// Date is when the block was allocated, IANA publishes these at the
+	// resolution of a month
+	Date time.Time
+
+	// Whois is the WHOIS server holding further details of the block
+	Whois string
+
+	// Status is the status of the block
+	Status Status
+}
+
+func init() {
+	DefaultRegistry = NewRegistry([]*Allocation{
+		{getFromCIDR("0.0.0.0/8"), "IANA - Local Identification", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("1.0.0.0/8"), "APNIC", "APNIC", getDate("2010-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2009-09"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("3.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("4.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1992-12"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("5.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2010-11"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("6.0.0.0/8"), "Army Information Systems Center", "ARIN", getDate("1994-02"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("7.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1995-04"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("8.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1992-12"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("9.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1992-08"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("10.0.0.0/8"), "IANA - Private Use", "", getDate("1995-06"), "", StatusReserved},
+		{getFromCIDR("11.0.0.0/8"), "DoD Intel Information Systems", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("12.0.0.0/8"), "AT&T Bell Laboratories", "ARIN", getDate("1995-06"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("13.0.0.0/8"), "Xerox Corporation", "ARIN", getDate("1991-09"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("14.0.0.0/8"), "APNIC", "APNIC", getDate("2010-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("15.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("16.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-11"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("17.0.0.0/8"), "Apple Computer Inc.", "ARIN", getDate("1992-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("18.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-01"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("19.0.0.0/8"), "Ford Motor Company", "ARIN", getDate("1995-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("20.0.0.0/8"), "Computer Sciences Corporation", "ARIN", getDate("1994-10"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("21.0.0.0/8"), "DDN-RVN", "ARIN", getDate("1991-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("22.0.0.0/8"), "Defense Information Systems Agency", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("23.0.0.0/8"), "ARIN", "ARIN", getDate("2010-11"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("24.0.0.0/8"), "ARIN", "ARIN", getDate("2001-05"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("25.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1995-01"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("26.0.0.0/8"), "Defense Information Systems Agency", "ARIN", getDate("1995-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("27.0.0.0/8"), "APNIC", "APNIC", getDate("2010-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("28.0.0.0/8"), "DSI-North", "ARIN", getDate("1992-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("29.0.0.0/8"), "Defense Information Systems Agency", "ARIN", getDate("1991-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("30.0.0.0/8"), "Defense Information Systems Agency", "ARIN", getDate("1991-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("31.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2010-05"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("32.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-06"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("33.0.0.0/8"), "DLA Systems Automation Center", "ARIN", getDate("1991-01"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("34.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-03"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("35.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-04"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("36.0.0.0/8"), "APNIC", "APNIC", getDate("2010-10"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("37.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2010-11"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("38.0.0.0/8"), "PSINet, Inc.", "ARIN", getDate("1994-09"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("39.0.0.0/8"), "APNIC", "APNIC", getDate("2011-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("40.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1994-06"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("41.0.0.0/8"), "AFRINIC", "AFRINIC", getDate("2005-04"), "whois.afrinic.net", StatusAllocated},
+		{getFromCIDR("42.0.0.0/8"), "APNIC", "APNIC", getDate("2010-10"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("43.0.0.0/8"), "Administered by APNIC", "APNIC", getDate("1991-01"), "whois.apnic.net", StatusLegacy},
+		{getFromCIDR("44.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1992-07"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("45.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1995-01"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("46.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2009-09"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("47.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1991-01"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("48.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1995-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("49.0.0.0/8"), "APNIC", "APNIC", getDate("2010-08"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("50.0.0.0/8"), "ARIN", "ARIN", getDate("2010-02"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("51.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1994-08"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("52.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1991-12"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("53.0.0.0/8"), "Daimler AG", "RIPE NCC", getDate("1993-10"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("54.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1992-03"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("55.0.0.0/8"), "DoD Network Information Center", "ARIN", getDate("1995-04"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("56.0.0.0/8"), "US Postal Service", "ARIN", getDate("1994-06"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("57.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1995-05"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("58.0.0.0/8"), "APNIC", "APNIC", getDate("2004-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("59.0.0.0/8"), "APNIC", "APNIC", getDate("2004-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("60.0.0.0/8"), "APNIC", "APNIC", getDate("2003-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("61.0.0.0/8"), "APNIC", "APNIC", getDate("1997-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("62.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("1997-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("63.0.0.0/8"), "ARIN", "ARIN", getDate("1997-04"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("64.0.0.0/8"), "ARIN", "ARIN", getDate("1999-07"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("65.0.0.0/8"), "ARIN", "ARIN", getDate("2000-07"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("66.0.0.0/8"), "ARIN", "ARIN", getDate("2000-07"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("67.0.0.0/8"), "ARIN", "ARIN", getDate("2001-05"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("68.0.0.0/8"), "ARIN", "ARIN", getDate("2001-06"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("69.0.0.0/8"), "ARIN", "ARIN", getDate("2002-08"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("70.0.0.0/8"), "ARIN", "ARIN", getDate("2004-01"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("71.0.0.0/8"), "ARIN", "ARIN", getDate("2004-08"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("72.0.0.0/8"), "ARIN", "ARIN", getDate("2004-08"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("73.0.0.0/8"), "ARIN", "ARIN", getDate("2005-03"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("74.0.0.0/8"), "ARIN", "ARIN", getDate("2005-06"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("75.0.0.0/8"), "ARIN", "ARIN", getDate("2005-06"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("76.0.0.0/8"), "ARIN", "ARIN", getDate("2005-06"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("77.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2006-08"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("78.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2006-08"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("79.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2006-08"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("80.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2001-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("81.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2001-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("82.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2002-11"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("83.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2003-11"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("84.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2003-11"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("85.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2004-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("86.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2004-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("87.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2004-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("88.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2004-04"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("89.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2005-06"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("90.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2005-06"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("91.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2005-06"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("92.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2007-03"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("93.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2007-03"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("94.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2007-07"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("95.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2007-07"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("96.0.0.0/8"), "ARIN", "ARIN", getDate("2006-10"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("97.0.0.0/8"), "ARIN", "ARIN", getDate("2006-10"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("98.0.0.0/8"), "ARIN", "ARIN", getDate("2006-10"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("99.0.0.0/8"), "ARIN", "ARIN", getDate("2006-10"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("100.0.0.0/8"), "ARIN", "ARIN", getDate("2010-11"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("101.0.0.0/8"), "APNIC", "APNIC", getDate("2010-08"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("102.0.0.0/8"), "AFRINIC", "AFRINIC", getDate("2011-02"), "whois.afrinic.net", StatusAllocated},
+		{getFromCIDR("103.0.0.0/8"), "APNIC", "APNIC", getDate("2011-02"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("104.0.0.0/8"), "ARIN", "ARIN", getDate("2011-02"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("105.0.0.0/8"), "AFRINIC", "AFRINIC", getDate("2010-11"), "whois.afrinic.net", StatusAllocated},
+		{getFromCIDR("106.0.0.0/8"), "APNIC", "APNIC", getDate("2011-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("107.0.0.0/8"), "ARIN", "ARIN", getDate("2010-02"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("108.0.0.0/8"), "ARIN", "ARIN", getDate("2008-12"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("109.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2009-01"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("110.0.0.0/8"), "APNIC", "APNIC", getDate("2008-11"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("111.0.0.0/8"), "APNIC", "APNIC", getDate("2008-11"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("112.0.0.0/8"), "APNIC", "APNIC", getDate("2008-05"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("113.0.0.0/8"), "APNIC", "APNIC", getDate("2008-05"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("114.0.0.0/8"), "APNIC", "APNIC", getDate("2007-10"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("115.0.0.0/8"), "APNIC", "APNIC", getDate("2007-10"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("116.0.0.0/8"), "APNIC", "APNIC", getDate("2007-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("117.0.0.0/8"), "APNIC", "APNIC", getDate("2007-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("118.0.0.0/8"), "APNIC", "APNIC", getDate("2007-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("119.0.0.0/8"), "APNIC", "APNIC", getDate("2007-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("120.0.0.0/8"), "APNIC", "APNIC", getDate("2007-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("121.0.0.0/8"), "APNIC", "APNIC", getDate("2006-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("122.0.0.0/8"), "APNIC", "APNIC", getDate("2006-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("123.0.0.0/8"), "APNIC", "APNIC", getDate("2006-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("124.0.0.0/8"), "APNIC", "APNIC", getDate("2005-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("125.0.0.0/8"), "APNIC", "APNIC", getDate("2005-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("126.0.0.0/8"), "APNIC", "APNIC", getDate("2005-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("127.0.0.0/8"), "IANA - Loopback", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("128.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("129.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("130.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("131.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("132.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("133.0.0.0/8"), "Administered by APNIC", "APNIC", getDate("1997-03"), "whois.apnic.net", StatusLegacy},
+		{getFromCIDR("134.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("135.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("136.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("137.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("138.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("139.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("140.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("141.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("142.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("143.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("144.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("145.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("146.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("147.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("148.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("149.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("150.0.0.0/8"), "Administered by APNIC", "APNIC", getDate("1993-05"), "whois.apnic.net", StatusLegacy},
+		{getFromCIDR("151.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("152.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("153.0.0.0/8"), "Administered by APNIC", "APNIC", getDate("1993-05"), "whois.apnic.net", StatusLegacy},
+		{getFromCIDR("154.0.0.0/8"), "Administered by AFRINIC", "AFRINIC", getDate("1993-05"), "whois.afrinic.net", StatusLegacy},
+		{getFromCIDR("155.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("156.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("157.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("158.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("159.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("160.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("161.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("162.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("163.0.0.0/8"), "Administered by APNIC", "APNIC", getDate("1993-05"), "whois.apnic.net", StatusLegacy},
+		{getFromCIDR("164.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("165.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("166.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("167.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("168.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("169.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("170.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("171.0.0.0/8"), "Administered by APNIC", "APNIC", getDate("1993-05"), "whois.apnic.net", StatusLegacy},
+		{getFromCIDR("172.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("173.0.0.0/8"), "ARIN", "ARIN", getDate("2008-02"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("174.0.0.0/8"), "ARIN", "ARIN", getDate("2008-02"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("175.0.0.0/8"), "APNIC", "APNIC", getDate("2009-08"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("176.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2010-05"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("177.0.0.0/8"), "LACNIC", "LACNIC", getDate("2010-06"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("178.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2009-01"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("179.0.0.0/8"), "LACNIC", "LACNIC", getDate("2011-02"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("180.0.0.0/8"), "APNIC", "APNIC", getDate("2009-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("181.0.0.0/8"), "LACNIC", "LACNIC", getDate("2010-06"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("182.0.0.0/8"), "APNIC", "APNIC", getDate("2009-08"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("183.0.0.0/8"), "APNIC", "APNIC", getDate("2009-08"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("184.0.0.0/8"), "ARIN", "ARIN", getDate("2008-12"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("185.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2011-02"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("186.0.0.0/8"), "LACNIC", "LACNIC", getDate("2007-09"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("187.0.0.0/8"), "LACNIC", "LACNIC", getDate("2007-09"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("188.0.0.0/8"), "Administered by RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusLegacy},
+		{getFromCIDR("189.0.0.0/8"), "LACNIC", "LACNIC", getDate("1995-06"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("190.0.0.0/8"), "LACNIC", "LACNIC", getDate("1995-06"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("191.0.0.0/8"), "Administered by LACNIC", "LACNIC", getDate("1993-05"), "whois.lacnic.net", StatusLegacy},
+		{getFromCIDR("192.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("193.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("194.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("195.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("1993-05"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("196.0.0.0/8"), "Administered by AFRINIC", "AFRINIC", getDate("1993-05"), "whois.afrinic.net", StatusLegacy},
+		{getFromCIDR("197.0.0.0/8"), "AFRINIC", "AFRINIC", getDate("2008-10"), "whois.afrinic.net", StatusAllocated},
+		{getFromCIDR("198.0.0.0/8"), "Administered by ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("199.0.0.0/8"), "ARIN", "ARIN", getDate("1993-05"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("200.0.0.0/8"), "LACNIC", "LACNIC", getDate("2002-11"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("201.0.0.0/8"), "LACNIC", "LACNIC", getDate("2003-04"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("202.0.0.0/8"), "APNIC", "APNIC", getDate("1993-05"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("203.0.0.0/8"), "APNIC", "APNIC", getDate("1993-05"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("204.0.0.0/8"), "ARIN", "ARIN", getDate("1994-03"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("205.0.0.0/8"), "ARIN", "ARIN", getDate("1994-03"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("206.0.0.0/8"), "ARIN", "ARIN", getDate("1995-04"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("207.0.0.0/8"), "ARIN", "ARIN", getDate("1995-11"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("208.0.0.0/8"), "ARIN", "ARIN", getDate("1996-04"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("209.0.0.0/8"), "ARIN", "ARIN", getDate("1996-06"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("210.0.0.0/8"), "APNIC", "APNIC", getDate("1996-06"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("211.0.0.0/8"), "APNIC", "APNIC", getDate("1996-06"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("212.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("1997-10"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("213.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("1993-10"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("214.0.0.0/8"), "US-DOD", "ARIN", getDate("1998-03"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("215.0.0.0/8"), "US-DOD", "ARIN", getDate("1998-03"), "whois.arin.net", StatusLegacy},
+		{getFromCIDR("216.0.0.0/8"), "ARIN", "ARIN", getDate("1998-04"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("217.0.0.0/8"), "RIPE NCC", "RIPE NCC", getDate("2000-06"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("218.0.0.0/8"), "APNIC", "APNIC", getDate("2000-12"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("219.0.0.0/8"), "APNIC", "APNIC", getDate("2001-09"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("220.0.0.0/8"), "APNIC", "APNIC", getDate("2001-12"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("221.0.0.0/8"), "APNIC", "APNIC", getDate("2002-07"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("222.0.0.0/8"), "APNIC", "APNIC", getDate("2003-02"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("223.0.0.0/8"), "APNIC", "APNIC", getDate("2010-04"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("224.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("225.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("226.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("227.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("228.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("229.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("230.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("231.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("232.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("233.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("234.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("235.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("236.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("237.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("238.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("239.0.0.0/8"), "Multicast", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("240.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("241.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("242.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("243.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("244.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("245.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("246.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("247.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("248.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("249.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("250.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("251.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("252.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("253.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("254.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("255.0.0.0/8"), "Future use", "", getDate("1981-09"), "", StatusReserved},
+		{getFromCIDR("2001::/23"), "IANA", "", getDate("1999-07"), "", StatusReserved},
+		{getFromCIDR("2001:200::/23"), "APNIC", "APNIC", getDate("1999-07"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2001:400::/23"), "ARIN", "ARIN", getDate("1999-07"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2001:600::/23"), "RIPE NCC", "RIPE NCC", getDate("1999-07"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:800::/22"), "RIPE NCC", "RIPE NCC", getDate("2002-11"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:c00::/23"), "APNIC", "APNIC", getDate("2002-05"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2001:e00::/23"), "APNIC", "APNIC", getDate("2003-01"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2001:1200::/23"), "LACNIC", "LACNIC", getDate("2002-11"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("2001:1400::/22"), "RIPE NCC", "RIPE NCC", getDate("2003-02"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:1800::/23"), "ARIN", "ARIN", getDate("2003-04"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2001:1a00::/23"), "RIPE NCC", "RIPE NCC", getDate("2004-01"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:1c00::/22"), "RIPE NCC", "RIPE NCC", getDate("2004-05"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:2000::/19"), "RIPE NCC", "RIPE NCC", getDate("2019-03"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:4000::/23"), "RIPE NCC", "RIPE NCC", getDate("2004-06"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:4200::/23"), "AFRINIC", "AFRINIC", getDate("2004-06"), "whois.afrinic.net", StatusAllocated},
+		{getFromCIDR("2001:4400::/23"), "APNIC", "APNIC", getDate("2004-06"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2001:4600::/23"), "RIPE NCC", "RIPE NCC", getDate("2004-08"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:4800::/23"), "ARIN", "ARIN", getDate("2004-08"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2001:4a00::/23"), "RIPE NCC", "RIPE NCC", getDate("2004-10"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:4c00::/23"), "RIPE NCC", "RIPE NCC", getDate("2004-12"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:5000::/20"), "RIPE NCC", "RIPE NCC", getDate("2004-09"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2001:8000::/19"), "APNIC", "APNIC", getDate("2004-11"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2001:a000::/20"), "APNIC", "APNIC", getDate("2004-11"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2001:b000::/20"), "APNIC", "APNIC", getDate("2006-03"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2002::/16"), "6to4", "", getDate("2001-02"), "", StatusAllocated},
+		{getFromCIDR("2003::/18"), "RIPE NCC", "RIPE NCC", getDate("2005-01"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2400::/12"), "APNIC", "APNIC", getDate("2006-10"), "whois.apnic.net", StatusAllocated},
+		{getFromCIDR("2600::/12"), "ARIN", "ARIN", getDate("2006-10"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2610::/23"), "ARIN", "ARIN", getDate("2005-11"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2620::/23"), "ARIN", "ARIN", getDate("2006-09"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2630::/12"), "ARIN", "ARIN", getDate("2019-11"), "whois.arin.net", StatusAllocated},
+		{getFromCIDR("2800::/12"), "LACNIC", "LACNIC", getDate("2006-10"), "whois.lacnic.net", StatusAllocated},
+		{getFromCIDR("2a00::/12"), "RIPE NCC", "RIPE NCC", getDate("2006-10"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2a10::/12"), "RIPE NCC", "RIPE NCC", getDate("2019-06"), "whois.ripe.net", StatusAllocated},
+		{getFromCIDR("2c00::/12"), "AFRINIC", "AFRINIC", getDate("2006-10"), "whois.afrinic.net", StatusAllocated},
+		{getFromCIDR("2d00::/8"), "IANA", "", getDate("1999-07"), "", StatusReserved},
+		{getFromCIDR("2e00::/7"), "IANA", "", getDate("1999-07"), "", StatusReserved},
+		{getFromCIDR("3000::/4"), "IANA", "", getDate("1999-07"), "", StatusReserved},
+		{getFromCIDR("3ffe::/16"), "IANA", "", getDate("2008-04"), "", StatusReserved},
+		{getFromCIDR("5f00::/8"), "IANA", "", getDate("2008-04"), "", StatusReserved},
+	})
+}
+
+// GetAllocationForIP returns the allocation the supplied IP is part of, or
+// nil if it is not in an allocated block. It queries DefaultRegistry
+func GetAllocationForIP(ip net.IP) *Allocation {
+	return DefaultRegistry.GetAllocationForIP(ip)
+}
+
+// GetAllocationsForNetwork returns a list of any allocations that are either
+// part of the supplied network or that the supplied network is part of. It
+// queries DefaultRegistry
+func GetAllocationsForNetwork(n iplib.Net) []*Allocation {
+	return DefaultRegistry.GetAllocationsForNetwork(n)
+}
+
+// GetRIRForIP returns the Regional Internet Registry responsible for the
+// supplied IP, or the empty string if there is none. It queries
+// DefaultRegistry
+func GetRIRForIP(ip net.IP) string {
+	if a := DefaultRegistry.GetAllocationForIP(ip); a != nil {
+		return a.RIR
+	}
+	return ""
+}
+
+// Unallocated returns the networks of the given IP version that IANA has not
+// allocated. It queries DefaultRegistry, see Registry.Unallocated() for
+// details
+func Unallocated(version int) []iplib.Net {
+	return DefaultRegistry.Unallocated(version)
+}
+
+func getFromCIDR(s string) iplib.Net {
+	_, n, _ := iplib.ParseCIDR(s)
+	return n
+}
+
+func getDate(s string) time.Time {
+	t, _ := time.Parse("2006-01", s)
+	return t
+}
@@ -0,0 +1,174 @@
+package rir
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kenits/iplib"
+)
+
+var IPTests = []struct {
+	name        string
+	address     string
+	designation string
+	rir         string
+	status      Status
+}{
+	{"Allocatedv4", "1.1.1.1", "APNIC", APNIC, StatusAllocated},
+	{"LegacyAdministeredv4", "3.5.140.2", "Administered by ARIN", ARIN, StatusLegacy},
+	{"LegacyOrgv4", "53.1.2.3", "Daimler AG", RIPENCC, StatusLegacy},
+	{"LACNICv4", "200.1.2.3", "LACNIC", LACNIC, StatusAllocated},
+	{"AFRINICv4", "41.0.0.1", "AFRINIC", AFRINIC, StatusAllocated},
+	{"Privatev4", "10.1.1.1", "IANA - Private Use", "", StatusReserved},
+	{"Multicastv4", "239.1.1.1", "Multicast", "", StatusReserved},
+	{"Mappedv4", "::ffff:8.8.8.8", "Administered by ARIN", ARIN, StatusLegacy},
+	{"Allocatedv6", "2001:db8::1", "APNIC", APNIC, StatusAllocated},
+	{"ARINv6", "2600:1f18::1", "ARIN", ARIN, StatusAllocated},
+	{"RIPEv6", "2a02:c7f::1", "RIPE NCC", RIPENCC, StatusAllocated},
+	{"Reservedv6", "2001::1", "IANA", "", StatusReserved},
+}
+
+func TestGetAllocationForIP(t *testing.T) {
+	for _, tt := range IPTests {
+		a := GetAllocationForIP(net.ParseIP(tt.address))
+		if a == nil {
+			t.Errorf("'%s' expected an allocation for %s", tt.name, tt.address)
+			continue
+		}
+		if a.Designation != tt.designation || a.RIR != tt.rir || a.Status != tt.status {
+			t.Errorf("'%s' expected '%s' '%s' %s, got '%s' '%s' %s", tt.name, tt.designation, tt.rir, tt.status, a.Designation, a.RIR, a.Status)
+		}
+		if r := GetRIRForIP(net.ParseIP(tt.address)); r != tt.rir {
+			t.Errorf("'%s' expected RIR '%s', got '%s'", tt.name, tt.rir, r)
+		}
+	}
+
+	for _, s := range []string{"fe80::1", "4000::1"} {
+		if a := GetAllocationForIP(net.ParseIP(s)); a != nil {
+			t.Errorf("expected no allocation for %s, got %s", s, a.Network.String())
+		}
+	}
+}
+
+var NetTests = []struct {
+	name    string
+	network string
+	count   int
+}{
+	{"Exactv4", "1.0.0.0/8", 1},
+	{"Insidev4", "1.1.1.0/24", 1},
+	{"Containsv4", "0.0.0.0/6", 4},
+	{"Everythingv4", "0.0.0.0/0", 256},
+	{"Insidev6", "2001:db8::/32", 1},
+	{"Containsv6", "2001::/20", 7},
+	{"Unassignedv6", "6000::/3", 0},
+}
+
+func TestGetAllocationsForNetwork(t *testing.T) {
+	for _, tt := range NetTests {
+		_, n, _ := iplib.ParseCIDR(tt.network)
+		a := GetAllocationsForNetwork(n)
+		if len(a) != tt.count {
+			t.Errorf("'%s' expected %d allocations, got %d", tt.name, tt.count, len(a))
+		}
+		for i := 1; i < len(a); i++ {
+			if iplib.CompareNets(a[i-1].Network, a[i].Network) >= 0 {
+				t.Errorf("'%s' allocations out of order at %d", tt.name, i)
+			}
+		}
+	}
+}
+
+func TestRegistry_Merge(t *testing.T) {
+	r := DefaultRegistry.Clone()
+	before := len(r.Allocations())
+
+	r.Merge(
+		&Allocation{Network: getFromCIDR("2001:2000::/20"), Designation: "RIPE NCC", RIR: RIPENCC},
+		&Allocation{Network: getFromCIDR("1.0.0.0/9"), Designation: "Test"},
+	)
+
+	if l := len(r.Allocations()); l != before {
+		t.Errorf("expected %d allocations after merge, got %d", before, l)
+	}
+	if a := r.GetAllocationForIP(net.ParseIP("2001:3000::1")); a != nil {
+		t.Errorf("expected 2001:3000::1 to be unallocated after merge, got %s", a.Network.String())
+	}
+	if a := r.GetAllocationForIP(net.ParseIP("1.1.1.1")); a == nil || a.Designation != "Test" {
+		t.Error("expected 1.1.1.1 to be in merged allocation")
+	}
+	if a := r.GetAllocationForIP(net.ParseIP("1.200.1.1")); a != nil {
+		t.Errorf("expected 1.200.1.1 to be unallocated after merge, got %s", a.Network.String())
+	}
+	if a := DefaultRegistry.GetAllocationForIP(net.ParseIP("1.1.1.1")); a.Designation != "APNIC" {
+		t.Error("expected merge into a clone to leave DefaultRegistry alone")
+	}
+}
+
+func TestRegistry_Nested(t *testing.T) {
+	for _, r := range []*Registry{DefaultRegistry, NewRegistry(DefaultRegistry.Allocations())} {
+		if a := r.GetAllocationForIP(net.ParseIP("3001::1")); a == nil || a.Network.String() != "3000::/4" || a.Status != StatusReserved {
+			t.Errorf("expected 3001::1 to be in reserved 3000::/4, got %v", a)
+		}
+		if a := r.GetAllocationForIP(net.ParseIP("3ffe::1")); a == nil || a.Network.String() != "3ffe::/16" {
+			t.Errorf("expected 3ffe::1 to be in 3ffe::/16, got %v", a)
+		}
+		a := r.GetAllocationsForNetwork(getFromCIDR("3ffe:1::/32"))
+		if len(a) != 2 || a[0].Network.String() != "3000::/4" || a[1].Network.String() != "3ffe::/16" {
+			t.Errorf("expected 3ffe:1::/32 to be in both 3000::/4 and 3ffe::/16, got %d allocations", len(a))
+		}
+	}
+
+	r := NewRegistry([]*Allocation{
+		{Network: getFromCIDR("10.0.0.0/8"), Designation: "Outer"},
+		{Network: getFromCIDR("10.1.0.0/16"), Designation: "Inner"},
+		{Network: getFromCIDR("10.1.0.0/16"), Designation: "Duplicate"},
+	})
+	if l := len(r.Allocations()); l != 2 {
+		t.Errorf("expected the duplicate to replace the first, got %d allocations", l)
+	}
+	if a := r.GetAllocationForIP(net.ParseIP("10.1.1.1")); a.Designation != "Duplicate" {
+		t.Errorf("expected 10.1.1.1 in the most specific allocation, got %s", a.Designation)
+	}
+	if a := r.GetAllocationForIP(net.ParseIP("10.2.1.1")); a.Designation != "Outer" {
+		t.Errorf("expected 10.2.1.1 in the enclosing allocation, got %s", a.Designation)
+	}
+}
+
+func TestUnallocated(t *testing.T) {
+	v4 := []string{"0.0.0.0/8", "10.0.0.0/8", "127.0.0.0/8", "224.0.0.0/3"}
+	nets := Unallocated(4)
+	if len(nets) != len(v4) {
+		t.Fatalf("expected %d v4 networks, got %d", len(v4), len(nets))
+	}
+	for i, n := range nets {
+		if n.String() != v4[i] {
+			t.Errorf("expected %s at %d, got %s", v4[i], i, n.String())
+		}
+	}
+
+	for _, n := range Unallocated(6) {
+		if a := GetAllocationsForNetwork(n); len(a) > 0 && a[0].Status == StatusAllocated {
+			t.Errorf("unallocated %s overlaps allocation %s", n.String(), a[0].Network.String())
+		}
+	}
+	for _, s := range []string{"2001::1", "2d00::1", "3fff::1"} {
+		if !containsIP(Unallocated(6), net.ParseIP(s)) {
+			t.Errorf("expected %s to be unallocated", s)
+		}
+	}
+	for _, s := range []string{"2001:200::1", "2a00::1", "fe80::1"} {
+		if containsIP(Unallocated(6), net.ParseIP(s)) {
+			t.Errorf("expected %s to not be unallocated", s)
+		}
+	}
+}
+
+func containsIP(nets []iplib.Net, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
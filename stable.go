@@ -0,0 +1,60 @@
+package iplib
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"net"
+)
+
+// StableAddress maps key, which can be anything that identifies a host such
+// as its hostname or MAC address, to a usable address in the represented v4
+// network. The same key, network and exclusions always produce the same
+// address, which makes it the v4 counterpart of iid.GenerateRFC7217Addr().
+//
+// The key is hashed with SHA256 to pick a starting point among the usable
+// addresses, which excludes the network and broadcast addresses unless the
+// block is a /31 or /32. If that address is in exclude, a second value from
+// the hash sets the step of a probe sequence that visits every usable address
+// exactly once, so each key follows its own path through the block. If every
+// usable address is excluded ErrNoAddressAvailable is returned, if the
+// network is not v4 ErrNotIP4 is
+func (n Net) StableAddress(key []byte, exclude []net.IP) (net.IP, error) {
+	if n.version != 4 {
+		return nil, ErrNotIP4
+	}
+
+	first := IP4ToUint32(n.FirstAddress())
+	size := uint64(IP4ToUint32(n.LastAddress())-first) + 1
+
+	excluded := make(map[uint32]bool, len(exclude))
+	for _, ip := range exclude {
+		if ip4 := ip.To4(); ip4 != nil {
+			excluded[IP4ToUint32(ip4)] = true
+		}
+	}
+
+	h := sha256.Sum256(key)
+	idx := binary.BigEndian.Uint64(h[0:8]) % size
+	step := uint64(1)
+	if size > 1 {
+		step = 1 + binary.BigEndian.Uint64(h[8:16])%(size-1)
+		for gcd(step, size) != 1 {
+			step = step%(size-1) + 1
+		}
+	}
+
+	for i := uint64(0); i < size; i++ {
+		if ip := first + uint32(idx); !excluded[ip] {
+			return Uint32ToIP4(ip), nil
+		}
+		idx = (idx + step) % size
+	}
+	return nil, ErrNoAddressAvailable
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,107 @@
+package iplib
+
+import (
+	"net"
+	"testing"
+)
+
+var StableAddressTests = []struct {
+	name string
+	cidr string
+	key  string
+	err  error
+}{
+	{"Slash8", "10.0.0.0/8", "web01.example.com", nil},
+	{"Slash24", "192.168.1.0/24", "web01.example.com", nil},
+	{"Slash24MAC", "192.168.1.0/24", "\x00\x1b\x63\x84\x45\xe6", nil},
+	{"Slash30", "192.168.1.4/30", "web01.example.com", nil},
+	{"Slash31", "192.168.1.4/31", "web01.example.com", nil},
+	{"Slash32", "192.168.1.4/32", "web01.example.com", nil},
+	{"IP6", "2001:db8::/64", "web01.example.com", ErrNotIP4},
+}
+
+func TestNet_StableAddress(t *testing.T) {
+	for _, tt := range StableAddressTests {
+		_, n, _ := ParseCIDR(tt.cidr)
+		ip, err := n.StableAddress([]byte(tt.key), nil)
+		if err != tt.err {
+			t.Errorf("%s: expected error '%v' got '%v'", tt.name, tt.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if CompareIPs(ip, n.FirstAddress()) < 0 || CompareIPs(ip, n.LastAddress()) > 0 {
+			t.Errorf("%s: %s is outside the usable range of %s", tt.name, ip, tt.cidr)
+		}
+		if again, _ := n.StableAddress([]byte(tt.key), nil); !again.Equal(ip) {
+			t.Errorf("%s: not deterministic, got %s then %s", tt.name, ip, again)
+		}
+	}
+}
+
+func TestNet_StableAddressSpread(t *testing.T) {
+	_, n, _ := ParseCIDR("192.168.1.0/24")
+	seen := make(map[string]bool)
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		ip, _ := n.StableAddress([]byte(key), nil)
+		seen[ip.String()] = true
+	}
+	if len(seen) < 6 {
+		t.Errorf("expected different keys to spread across the block, got %d distinct addresses from 8", len(seen))
+	}
+}
+
+func TestNet_StableAddressExclude(t *testing.T) {
+	_, n, _ := ParseCIDR("192.168.1.0/29")
+	key := []byte("web01.example.com")
+
+	// excluding each result in turn must visit every usable address once
+	exclude := []net.IP{}
+	for i := uint32(0); i < n.Count(); i++ {
+		ip, err := n.StableAddress(key, exclude)
+		if err != nil {
+			t.Fatalf("probe %d: unexpected error: %s", i, err)
+		}
+		for _, x := range exclude {
+			if x.Equal(ip) {
+				t.Fatalf("probe %d: got excluded address %s", i, ip)
+			}
+		}
+		if ip.Equal(n.NetworkAddress()) || ip.Equal(n.BroadcastAddress()) {
+			t.Fatalf("probe %d: got unusable address %s", i, ip)
+		}
+		exclude = append(exclude, ip)
+	}
+
+	if _, err := n.StableAddress(key, exclude); err != ErrNoAddressAvailable {
+		t.Errorf("expected ErrNoAddressAvailable got '%v'", err)
+	}
+}
+
+func TestNet_StableAddressProbeIsPerKey(t *testing.T) {
+	_, n, _ := ParseCIDR("192.168.1.0/24")
+	a, _ := n.StableAddress([]byte("web01"), nil)
+
+	// a key that starts on an excluded address should not simply take the
+	// next one up
+	collisions := 0
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		start, _ := n.StableAddress([]byte(key), nil)
+		next, _ := n.StableAddress([]byte(key), []net.IP{start})
+		if next.Equal(NextIP(start)) {
+			collisions++
+		}
+		if next.Equal(start) {
+			t.Errorf("%s: excluded address %s returned", key, start)
+		}
+	}
+	if collisions > 2 {
+		t.Errorf("probe sequence looks linear for %d of 8 keys", collisions)
+	}
+
+	if b, _ := n.StableAddress([]byte("web01"), []net.IP{net.ParseIP("10.0.0.1")}); !b.Equal(a) {
+		t.Errorf("an exclusion outside the block changed the address from %s to %s", a, b)
+	}
+}
@@ -0,0 +1,141 @@
+package iplib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+)
+
+// TeredoFlagCone is set in Teredo.Flags if the client is behind a cone NAT
+const TeredoFlagCone = 0x8000
+
+var (
+	prefix6to4   = []byte{0x20, 0x02}
+	prefixTeredo = []byte{0x20, 0x01, 0x00, 0x00}
+	isatapTag    = []byte{0x00, 0x00, 0x5e, 0xfe}
+)
+
+// Teredo holds the information encoded in an RFC4380 Teredo address. Port
+// and Client are stored as they appear on the wire, not in the obfuscated
+// form used within the address itself
+type Teredo struct {
+	// Server is the v4 address of the Teredo server
+	Server net.IP
+
+	// Flags describe the client, see TeredoFlagCone
+	Flags uint16
+
+	// Port is the external UDP port of the client
+	Port uint16
+
+	// Client is the external v4 address of the client
+	Client net.IP
+}
+
+// DecodeISATAPAddr returns the v4 address embedded in the Interface
+// Identifier of an RFC5214 ISATAP address, or ErrNoEmbeddedIP4 if the
+// address is not an ISATAP address
+func DecodeISATAPAddr(ip net.IP) (net.IP, error) {
+	if len(ip) != 16 || Version(ip) != 6 {
+		return nil, ErrNotIP6
+	}
+	if ip[8]&^0x03 != 0 || ip[9] != isatapTag[1] || ip[10] != isatapTag[2] || ip[11] != isatapTag[3] {
+		return nil, ErrNoEmbeddedIP4
+	}
+	return copyIP(ip[12:]), nil
+}
+
+// DecodeTeredoAddr takes an RFC4380 Teredo address, from the 2001::/32
+// block, and returns the server, client, port and flags encoded in it. If
+// the address is not a Teredo address ErrNoEmbeddedIP4 is returned
+func DecodeTeredoAddr(ip net.IP) (Teredo, error) {
+	if len(ip) != 16 || Version(ip) != 6 {
+		return Teredo{}, ErrNotIP6
+	}
+	if !bytes.Equal(ip[:4], prefixTeredo) {
+		return Teredo{}, ErrNoEmbeddedIP4
+	}
+
+	t := Teredo{
+		Server: copyIP(ip[4:8]),
+		Flags:  binary.BigEndian.Uint16(ip[8:10]),
+		Port:   binary.BigEndian.Uint16(ip[10:12]) ^ 0xffff,
+		Client: make(net.IP, 4),
+	}
+	for i, b := range ip[12:] {
+		t.Client[i] = b ^ 0xff
+	}
+	return t, nil
+}
+
+// Decode6to4Addr returns the v4 address embedded in an RFC3056 6to4 address,
+// from the 2002::/16 block. If the address is not a 6to4 address
+// ErrNoEmbeddedIP4 is returned
+func Decode6to4Addr(ip net.IP) (net.IP, error) {
+	if len(ip) != 16 || Version(ip) != 6 {
+		return nil, ErrNotIP6
+	}
+	if !bytes.Equal(ip[:2], prefix6to4) {
+		return nil, ErrNoEmbeddedIP4
+	}
+	return copyIP(ip[2:6]), nil
+}
+
+// MakeISATAPAddr takes a v6 prefix, assumed to be a /64, and a v4 address and
+// returns an RFC5214 ISATAP address. If the v4 address is globally unique
+// the 'u' bit of the Interface Identifier should be set by passing true as
+// global, for private addresses such as those in RFC1918 it should be false
+func MakeISATAPAddr(prefix, ip net.IP, global bool) (net.IP, error) {
+	if len(prefix) != 16 || Version(prefix) != 6 {
+		return nil, ErrNotIP6
+	}
+	if EffectiveVersion(ip) != 4 {
+		return nil, ErrNotIP4
+	}
+
+	xip := make(net.IP, 16)
+	copy(xip, prefix[:8])
+	copy(xip[8:], isatapTag)
+	copy(xip[12:], ip.To4())
+	if global {
+		xip[8] |= 0x02
+	}
+	return xip, nil
+}
+
+// MakeTeredoAddr returns the RFC4380 Teredo address described by t, with the
+// client port and address obfuscated as required by the RFC
+func MakeTeredoAddr(t Teredo) (net.IP, error) {
+	if EffectiveVersion(t.Server) != 4 || EffectiveVersion(t.Client) != 4 {
+		return nil, ErrNotIP4
+	}
+
+	xip := make(net.IP, 16)
+	copy(xip, prefixTeredo)
+	copy(xip[4:], t.Server.To4())
+	binary.BigEndian.PutUint16(xip[8:], t.Flags)
+	binary.BigEndian.PutUint16(xip[10:], t.Port^0xffff)
+	for i, b := range t.Client.To4() {
+		xip[12+i] = b ^ 0xff
+	}
+	return xip, nil
+}
+
+// Make6to4Net returns the RFC3056 6to4 /48 network belonging to the given v4
+// address
+func Make6to4Net(ip net.IP) (Net, error) {
+	if EffectiveVersion(ip) != 4 {
+		return Net{}, ErrNotIP4
+	}
+
+	xip := make(net.IP, 16)
+	copy(xip, prefix6to4)
+	copy(xip[2:], ip.To4())
+	return NewNet(xip, 48), nil
+}
+
+func copyIP(ip net.IP) net.IP {
+	xip := make(net.IP, len(ip))
+	copy(xip, ip)
+	return xip
+}
@@ -0,0 +1,175 @@
+package iplib
+
+import (
+	"net"
+	"testing"
+)
+
+var Tunnel6to4Tests = []struct {
+	ip4 string
+	net string
+	ip6 string
+}{
+	{
+		"192.0.2.1",
+		"2002:c000:201::/48",
+		"2002:c000:201:5::1",
+	},
+	{
+		"10.254.3.255",
+		"2002:afe:3ff::/48",
+		"2002:afe:3ff::",
+	},
+}
+
+func TestMake6to4Net(t *testing.T) {
+	for i, tt := range Tunnel6to4Tests {
+		n, err := Make6to4Net(net.ParseIP(tt.ip4))
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if n.String() != tt.net {
+			t.Errorf("[%d] expected %s got %s", i, tt.net, n.String())
+		}
+	}
+
+	if _, err := Make6to4Net(net.ParseIP("2001:db8::1")); err != ErrNotIP4 {
+		t.Errorf("expected ErrNotIP4 for v6 input, got '%v'", err)
+	}
+}
+
+func TestDecode6to4Addr(t *testing.T) {
+	for i, tt := range Tunnel6to4Tests {
+		ip, err := Decode6to4Addr(net.ParseIP(tt.ip6))
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if !ip.Equal(net.ParseIP(tt.ip4)) {
+			t.Errorf("[%d] expected %s got %s", i, tt.ip4, ip)
+		}
+	}
+
+	if _, err := Decode6to4Addr(net.ParseIP("2001:db8::1")); err != ErrNoEmbeddedIP4 {
+		t.Errorf("expected ErrNoEmbeddedIP4 for non-6to4 address, got '%v'", err)
+	}
+}
+
+var TeredoTests = []struct {
+	ip6    string
+	server string
+	flags  uint16
+	port   uint16
+	client string
+}{
+	{
+		"2001:0:4136:e378:8000:63bf:3fff:fdd2",
+		"65.54.227.120",
+		TeredoFlagCone,
+		40000,
+		"192.0.2.45",
+	},
+	{
+		"2001::cb00:7101:0:ffff:ffff:ffff",
+		"203.0.113.1",
+		0,
+		0,
+		"0.0.0.0",
+	},
+}
+
+func TestDecodeTeredoAddr(t *testing.T) {
+	for i, tt := range TeredoTests {
+		td, err := DecodeTeredoAddr(net.ParseIP(tt.ip6))
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if !td.Server.Equal(net.ParseIP(tt.server)) {
+			t.Errorf("[%d] expected server %s got %s", i, tt.server, td.Server)
+		}
+		if td.Flags != tt.flags {
+			t.Errorf("[%d] expected flags %04x got %04x", i, tt.flags, td.Flags)
+		}
+		if td.Port != tt.port {
+			t.Errorf("[%d] expected port %d got %d", i, tt.port, td.Port)
+		}
+		if !td.Client.Equal(net.ParseIP(tt.client)) {
+			t.Errorf("[%d] expected client %s got %s", i, tt.client, td.Client)
+		}
+	}
+
+	if _, err := DecodeTeredoAddr(net.ParseIP("2001:db8::1")); err != ErrNoEmbeddedIP4 {
+		t.Errorf("expected ErrNoEmbeddedIP4 for non-Teredo address, got '%v'", err)
+	}
+}
+
+func TestMakeTeredoAddr(t *testing.T) {
+	for i, tt := range TeredoTests {
+		td := Teredo{
+			Server: net.ParseIP(tt.server),
+			Flags:  tt.flags,
+			Port:   tt.port,
+			Client: net.ParseIP(tt.client),
+		}
+		ip, err := MakeTeredoAddr(td)
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if !ip.Equal(net.ParseIP(tt.ip6)) {
+			t.Errorf("[%d] expected %s got %s", i, tt.ip6, ip)
+		}
+	}
+}
+
+var ISATAPTests = []struct {
+	prefix string
+	ip4    string
+	global bool
+	ip6    string
+}{
+	{
+		"fe80::",
+		"192.168.1.1",
+		false,
+		"fe80::5efe:c0a8:101",
+	},
+	{
+		"2001:db8:1:2::",
+		"198.51.100.7",
+		true,
+		"2001:db8:1:2:200:5efe:c633:6407",
+	},
+}
+
+func TestMakeISATAPAddr(t *testing.T) {
+	for i, tt := range ISATAPTests {
+		ip, err := MakeISATAPAddr(net.ParseIP(tt.prefix), net.ParseIP(tt.ip4), tt.global)
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if !ip.Equal(net.ParseIP(tt.ip6)) {
+			t.Errorf("[%d] expected %s got %s", i, tt.ip6, ip)
+		}
+	}
+}
+
+func TestDecodeISATAPAddr(t *testing.T) {
+	for i, tt := range ISATAPTests {
+		ip, err := DecodeISATAPAddr(net.ParseIP(tt.ip6))
+		if err != nil {
+			t.Errorf("[%d] got unexpected error: %s", i, err)
+			continue
+		}
+		if !ip.Equal(net.ParseIP(tt.ip4)) {
+			t.Errorf("[%d] expected %s got %s", i, tt.ip4, ip)
+		}
+	}
+
+	if _, err := DecodeISATAPAddr(net.ParseIP("fe80::1")); err != ErrNoEmbeddedIP4 {
+		t.Errorf("expected ErrNoEmbeddedIP4 for non-ISATAP address, got '%v'", err)
+	}
+}
@@ -0,0 +1,177 @@
+package iplib
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// HostnameFunc returns the fully qualified hostname a PTR record for ip should
+// point to. Returning an empty string causes no record to be written for ip
+type HostnameFunc func(ip net.IP) string
+
+// SOARecord holds the fields of a DNS Start of Authority record
+type SOARecord struct {
+	// MName is the primary nameserver for the zone
+	MName string
+
+	// RName is the mailbox of the person responsible for the zone, in DNS
+	// form (hostmaster.example.com)
+	RName string
+
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
+}
+
+// ZoneConfig controls the output of WriteReverseZones
+type ZoneConfig struct {
+	// Origin is a template for the $ORIGIN of each zone. The string "{zone}"
+	// will be replaced with the zone name calculated by Net.ReverseZones(). If
+	// left blank the zone name is used as-is
+	Origin string
+
+	// TTL, if non-zero, is written as the $TTL of each zone
+	TTL uint32
+
+	// SOA, if set, is written at the apex of each zone
+	SOA *SOARecord
+
+	// NS, if set, is written at the apex of each zone as a list of
+	// authoritative nameservers
+	NS []string
+}
+
+// WriteReverseZones writes RFC1035 master-file records for each reverse zone
+// required to cover the represented network to w. Every usable address in the
+// network is passed to hostname and, if a name is returned, a PTR record is
+// written for it. Addresses are generated one at a time rather than
+// enumerated up front, so this is safe to use on large v4 blocks, but note
+// that it will happily try to walk a v6 /64 to the end.
+//
+// If the network requires RFC2317 classless delegation the CNAME records
+// pointing into the classless zone are written afterward, under the $ORIGIN
+// of the parent zone. The SOA and NS records are not written for the parent
+// as it is assumed to be managed elsewhere.
+//
+// hostname must not be nil, if it is ErrNoHostnameFunc is returned before
+// anything is written.
+func (n Net) WriteReverseZones(w io.Writer, conf ZoneConfig, hostname HostnameFunc) error {
+	if hostname == nil {
+		return ErrNoHostnameFunc
+	}
+	for _, z := range n.ReverseZones() {
+		origin := zoneOrigin(conf.Origin, z.Name)
+		if err := writeZoneHeader(w, origin, conf); err != nil {
+			return err
+		}
+
+		// hostname is only called once per address, the labels given a PTR
+		// in a classless zone are kept for the CNAMEs written below
+		labels := []string{}
+		err := n.walkZone(z, func(ip net.IP) error {
+			name := hostname(ip)
+			if name == "" {
+				return nil
+			}
+			label := zoneRelativeName(ip, z)
+			if z.Classless {
+				labels = append(labels, label)
+			}
+			_, err := fmt.Fprintf(w, "%s\tIN\tPTR\t%s\n", label, fqdn(name))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if !z.Classless {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "$ORIGIN %s\n", zoneOrigin(conf.Origin, z.Parent)); err != nil {
+			return err
+		}
+		for _, label := range labels {
+			if _, err := fmt.Fprintf(w, "%s\tIN\tCNAME\t%s.%s\n", label, label, origin); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkZone calls f for each usable address of n that falls within z,
+// stopping at the first error
+func (n Net) walkZone(z ReverseZone, f func(ip net.IP) error) error {
+	first, last := n.FirstAddress(), n.LastAddress()
+	if CompareIPs(z.Network.NetworkAddress(), first) > 0 {
+		first = z.Network.NetworkAddress()
+	}
+	if CompareIPs(z.Network.BroadcastAddress(), last) < 0 {
+		last = z.Network.BroadcastAddress()
+	}
+
+	for ip := first; CompareIPs(ip, last) <= 0; ip = NextIP(ip) {
+		if err := f(ip); err != nil {
+			return err
+		}
+		if CompareIPs(ip, last) == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+func writeZoneHeader(w io.Writer, origin string, conf ZoneConfig) error {
+	if _, err := fmt.Fprintf(w, "$ORIGIN %s\n", origin); err != nil {
+		return err
+	}
+	if conf.TTL > 0 {
+		if _, err := fmt.Fprintf(w, "$TTL %d\n", conf.TTL); err != nil {
+			return err
+		}
+	}
+	if soa := conf.SOA; soa != nil {
+		_, err := fmt.Fprintf(w, "@\tIN\tSOA\t%s %s (%d %d %d %d %d)\n",
+			fqdn(soa.MName), fqdn(soa.RName), soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minimum)
+		if err != nil {
+			return err
+		}
+	}
+	for _, ns := range conf.NS {
+		if _, err := fmt.Fprintf(w, "@\tIN\tNS\t%s\n", fqdn(ns)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zoneRelativeName returns the owner name of ip relative to the origin of z
+func zoneRelativeName(ip net.IP, z ReverseZone) string {
+	if z.Classless {
+		return fmt.Sprintf("%d", ForceIP4(ip)[3])
+	}
+	name := strings.TrimSuffix(IPToARPA(ip), z.Name)
+	if name == "" {
+		return "@"
+	}
+	return strings.TrimSuffix(name, ".")
+}
+
+func zoneOrigin(template, zone string) string {
+	if template == "" {
+		return fqdn(zone)
+	}
+	return fqdn(strings.Replace(template, "{zone}", zone, -1))
+}
+
+func fqdn(s string) string {
+	if strings.HasSuffix(s, ".") {
+		return s
+	}
+	return s + "."
+}
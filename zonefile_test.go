@@ -0,0 +1,165 @@
+package iplib
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+var ZoneFileTests = []struct {
+	in       string
+	conf     ZoneConfig
+	hostname HostnameFunc
+	out      string
+}{
+	{
+		"192.168.1.1/32",
+		ZoneConfig{},
+		nameAllHosts,
+		"$ORIGIN 1.1.168.192.in-addr.arpa.\n" +
+			"@\tIN\tPTR\thost-1.example.com.\n",
+	},
+	{
+		"192.168.1.0/24",
+		ZoneConfig{
+			TTL: 3600,
+			SOA: &SOARecord{"ns1.example.com", "hostmaster.example.com", 1, 7200, 900, 1209600, 300},
+			NS:  []string{"ns1.example.com", "ns2.example.com."},
+		},
+		nameNoHosts,
+		"$ORIGIN 1.168.192.in-addr.arpa.\n" +
+			"$TTL 3600\n" +
+			"@\tIN\tSOA\tns1.example.com. hostmaster.example.com. (1 7200 900 1209600 300)\n" +
+			"@\tIN\tNS\tns1.example.com.\n" +
+			"@\tIN\tNS\tns2.example.com.\n",
+	},
+	{
+		"192.168.1.64/30",
+		ZoneConfig{},
+		nameAllHosts,
+		"$ORIGIN 64/30.1.168.192.in-addr.arpa.\n" +
+			"65\tIN\tPTR\thost-65.example.com.\n" +
+			"66\tIN\tPTR\thost-66.example.com.\n" +
+			"$ORIGIN 1.168.192.in-addr.arpa.\n" +
+			"65\tIN\tCNAME\t65.64/30.1.168.192.in-addr.arpa.\n" +
+			"66\tIN\tCNAME\t66.64/30.1.168.192.in-addr.arpa.\n",
+	},
+	{
+		"192.168.1.64/30",
+		ZoneConfig{Origin: "{zone}.internal"},
+		nameAllHosts,
+		"$ORIGIN 64/30.1.168.192.in-addr.arpa.internal.\n" +
+			"65\tIN\tPTR\thost-65.example.com.\n" +
+			"66\tIN\tPTR\thost-66.example.com.\n" +
+			"$ORIGIN 1.168.192.in-addr.arpa.internal.\n" +
+			"65\tIN\tCNAME\t65.64/30.1.168.192.in-addr.arpa.internal.\n" +
+			"66\tIN\tCNAME\t66.64/30.1.168.192.in-addr.arpa.internal.\n",
+	},
+	{
+		"10.0.0.0/23",
+		ZoneConfig{},
+		nameEdgeHosts,
+		"$ORIGIN 0.0.10.in-addr.arpa.\n" +
+			"255\tIN\tPTR\thost-255.example.com.\n" +
+			"$ORIGIN 1.0.10.in-addr.arpa.\n" +
+			"0\tIN\tPTR\thost-0.example.com.\n",
+	},
+	{
+		"2001:db8::/126",
+		ZoneConfig{},
+		nameAllHosts,
+		"$ORIGIN 0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.\n" +
+			"@\tIN\tPTR\thost-0.example.com.\n" +
+			"$ORIGIN 1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.\n" +
+			"@\tIN\tPTR\thost-1.example.com.\n" +
+			"$ORIGIN 2.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.\n" +
+			"@\tIN\tPTR\thost-2.example.com.\n" +
+			"$ORIGIN 3.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.\n" +
+			"@\tIN\tPTR\thost-3.example.com.\n",
+	},
+}
+
+func nameAllHosts(ip net.IP) string {
+	return fmt.Sprintf("host-%d.example.com", ip[len(ip)-1])
+}
+
+func nameNoHosts(ip net.IP) string {
+	return ""
+}
+
+// nameEdgeHosts only names addresses ending in .0 or .255, which are usable
+// in the middle of blocks larger than a /24
+func nameEdgeHosts(ip net.IP) string {
+	if last := ip[len(ip)-1]; last == 0 || last == 255 {
+		return nameAllHosts(ip)
+	}
+	return ""
+}
+
+func TestNet_WriteReverseZones(t *testing.T) {
+	for i, tt := range ZoneFileTests {
+		_, n, _ := ParseCIDR(tt.in)
+		var buf bytes.Buffer
+		if err := n.WriteReverseZones(&buf, tt.conf, tt.hostname); err != nil {
+			t.Errorf("[%d] On Net{%s}.WriteReverseZones() got unexpected error: %s", i, tt.in, err)
+			continue
+		}
+		if buf.String() != tt.out {
+			t.Errorf("[%d] On Net{%s}.WriteReverseZones() expected:\n%s\ngot:\n%s", i, tt.in, tt.out, buf.String())
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("write failed")
+}
+
+func TestNet_WriteReverseZonesError(t *testing.T) {
+	_, n, _ := ParseCIDR("192.168.1.0/24")
+	err := n.WriteReverseZones(failingWriter{}, ZoneConfig{}, nameAllHosts)
+	if err == nil || !strings.Contains(err.Error(), "write failed") {
+		t.Errorf("expected writer error to be returned, got %v", err)
+	}
+}
+
+func TestNet_WriteReverseZonesCallsHostnameOnce(t *testing.T) {
+	_, n, _ := ParseCIDR("192.168.1.64/29")
+	calls := make(map[string]int)
+	hostname := func(ip net.IP) string {
+		calls[ip.String()]++
+		// only name each address the first time it is asked for, so a
+		// second call would leave a CNAME without a PTR or vice versa
+		if calls[ip.String()] > 1 {
+			return ""
+		}
+		return nameAllHosts(ip)
+	}
+
+	var buf bytes.Buffer
+	if err := n.WriteReverseZones(&buf, ZoneConfig{}, hostname); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for ip, c := range calls {
+		if c != 1 {
+			t.Errorf("hostname called %d times for %s", c, ip)
+		}
+	}
+	if ptr, cname := strings.Count(buf.String(), "\tPTR\t"), strings.Count(buf.String(), "\tCNAME\t"); ptr != 6 || cname != 6 {
+		t.Errorf("expected 6 PTR and 6 CNAME records, got %d and %d", ptr, cname)
+	}
+}
+
+func TestNet_WriteReverseZonesNilHostname(t *testing.T) {
+	_, n, _ := ParseCIDR("192.168.1.64/30")
+	var buf bytes.Buffer
+	if err := n.WriteReverseZones(&buf, ZoneConfig{}, nil); err != ErrNoHostnameFunc {
+		t.Errorf("expected ErrNoHostnameFunc, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written, got:\n%s", buf.String())
+	}
+}